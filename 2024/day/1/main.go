@@ -2,101 +2,908 @@ package main
 
 import (
 	"bufio"
+	"cmp"
+	"container/heap"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"math"
+	"io"
+	"maps"
+	"math/big"
+	"math/bits"
 	"os"
-	"regexp"
+	"path/filepath"
 	"slices"
 	"strconv"
+	"strings"
+
+	"aoc/intmath"
+	"aoc/stream"
+)
+
+var (
+	streamInput  = flag.Bool("stream", false, "count the location IDs line by line instead of loading the lists, for inputs too large for memory")
+	externalSort = flag.Bool("external", false, "sort the lists with an external merge sort through temporary files, for lists with too many distinct IDs for -stream")
+	runLength    = flag.Int("run-length", 1<<20, "location IDs per list that -external sorts in memory at a time")
+
+	reportFormat = flag.String("report", "", "also print a report on the lists as `text`, or print the answers and the report as a JSON document with json")
+	reportTop    = flag.Int("report-top", 10, "IDs listed per section of the report, 0 for all")
 )
 
 func main() {
-	left, right, err := readInput()
+	flag.Parse()
+
+	switch {
+	case *reportFormat != "" && *reportFormat != "text" && *reportFormat != "json":
+		fmt.Printf("invalid -report %q, expected text or json\n", *reportFormat)
+		return
+	case *reportFormat != "" && (*streamInput || *externalSort):
+		fmt.Println("-report needs the lists in memory and can't be combined with -stream or -external")
+		return
+	}
+
+	if *streamInput {
+		solveStreaming()
+		return
+	}
+	if *externalSort {
+		solveExternal()
+		return
+	}
+
+	columns, err := readInput()
 	if err != nil {
 		fmt.Printf("error reading input: %v", err)
 		return
 	}
 
-	sum, err := partOne(left, right)
-	if err != nil {
-		fmt.Printf("error calculating part one: %v", err)
+	distance := func(i, j int) (*intmath.Sum, error) { return partOne(columns[i], columns[j]) }
+	similarity := func(i, j int) (*intmath.Sum, error) { return partTwo(columns[i], columns[j]), nil }
+
+	// the JSON report is the whole output, answers included
+	if *reportFormat == "json" {
+		r := newReport(columns, *reportTop)
+		if r.Answers, err = newAnswers(len(columns), distance, similarity); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := printReport(r, *reportFormat); err != nil {
+			fmt.Printf("error printing report: %v", err)
+		}
 		return
 	}
 
+	if err := printAnswers(len(columns), distance, similarity); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if *reportFormat != "" {
+		if err := printReport(newReport(columns, *reportTop), *reportFormat); err != nil {
+			fmt.Printf("error printing report: %v", err)
+		}
+	}
+}
+
+// pairFunc computes an answer for the lists in columns i and j.
+type pairFunc func(i, j int) (*intmath.Sum, error)
+
+// printAnswers prints the answers for the first two columns, which are the
+// puzzle's lists, and with more columns the matrices of every pair of them.
+func printAnswers(columns int, distance, similarity pairFunc) error {
+	sum, err := distance(0, 1)
+	if err != nil {
+		return fmt.Errorf("error calculating part one: %w", err)
+	}
 	fmt.Printf("(Part one) sum of distances: %v\n", sum)
 
-	similarity := partTwo(left, right)
-	fmt.Printf("(Part two) similarity: %v\n", similarity)
+	score, err := similarity(0, 1)
+	if err != nil {
+		return fmt.Errorf("error calculating part two: %w", err)
+	}
+	fmt.Printf("(Part two) similarity: %v\n", score)
+
+	if columns == 2 {
+		return nil
+	}
+	if err := printMatrix("Sum of distances between columns", columns, distance); err != nil {
+		return err
+	}
+	return printMatrix("Similarity between columns", columns, similarity)
 }
 
-func readInput() (left, right []int, err error) {
+// answers are the answers in the JSON report.
+type answers struct {
+	PartOne json.Number `json:"part_one"`
+	PartTwo json.Number `json:"part_two"`
+	// with more than two columns, the matrices of every pair of them, null on
+	// the diagonal
+	Distances  [][]*json.Number `json:"distance_matrix,omitempty"`
+	Similarity [][]*json.Number `json:"similarity_matrix,omitempty"`
+}
+
+// newAnswers computes what printAnswers prints.
+func newAnswers(columns int, distance, similarity pairFunc) (answers, error) {
+	var a answers
+	sum, err := distance(0, 1)
+	if err != nil {
+		return a, fmt.Errorf("error calculating part one: %w", err)
+	}
+	score, err := similarity(0, 1)
+	if err != nil {
+		return a, fmt.Errorf("error calculating part two: %w", err)
+	}
+	a.PartOne, a.PartTwo = json.Number(sum.String()), json.Number(score.String())
+
+	if columns == 2 {
+		return a, nil
+	}
+	for _, m := range []struct {
+		value pairFunc
+		cells *[][]*json.Number
+	}{{distance, &a.Distances}, {similarity, &a.Similarity}} {
+		values, err := matrix(columns, m.value)
+		if err != nil {
+			return a, err
+		}
+		*m.cells = make([][]*json.Number, columns)
+		for i, row := range values {
+			(*m.cells)[i] = make([]*json.Number, columns)
+			for j, v := range row {
+				if i != j {
+					number := json.Number(v)
+					(*m.cells)[i][j] = &number
+				}
+			}
+		}
+	}
+	return a, nil
+}
+
+// matrix returns value(i, j) for every pair of distinct columns, empty on the
+// diagonal.
+func matrix(columns int, value pairFunc) ([][]string, error) {
+	cells := make([][]string, columns)
+	for i := range cells {
+		cells[i] = make([]string, columns)
+		for j := range cells[i] {
+			if i == j {
+				continue
+			}
+			v, err := value(i, j)
+			if err != nil {
+				return nil, fmt.Errorf("error calculating columns %d and %d: %w", i+1, j+1, err)
+			}
+			cells[i][j] = v.String()
+		}
+	}
+	return cells, nil
+}
+
+// printMatrix prints value(i, j) for every pair of distinct columns, numbered
+// from 1.
+func printMatrix(title string, columns int, value pairFunc) error {
+	cells, err := matrix(columns, value)
+	if err != nil {
+		return err
+	}
+	labelWidth := len(strconv.Itoa(columns))
+	width := labelWidth
+	for i, row := range cells {
+		cells[i][i] = "-"
+		for _, cell := range row {
+			width = max(width, len(cell))
+		}
+	}
+
+	fmt.Printf("\n%s:\n%*s", title, labelWidth, "")
+	for j := range columns {
+		fmt.Printf(" %*d", width, j+1)
+	}
+	fmt.Println()
+	for i, row := range cells {
+		fmt.Printf("%*d", labelWidth, i+1)
+		for _, cell := range row {
+			fmt.Printf(" %*s", width, cell)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// readInput reads one list per whitespace separated column.
+func readInput() ([][]int, error) {
 	file, err := os.Open("./input.txt")
 	if err != nil {
-		return nil, nil, fmt.Errorf("error opening file: %w", err)
+		return nil, fmt.Errorf("error opening file: %w", err)
 	}
 	defer file.Close()
 
-	leftSlice := make([]int, 0)
-	rightSlice := make([]int, 0)
+	var columns [][]int
 	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		re := regexp.MustCompile(`\s+`)
-		split := re.Split(line, -1)
-
-		leftValue, err := strconv.Atoi(split[0])
+	for n := 1; scanner.Scan(); n++ {
+		values, err := parseLine(n, scanner.Text(), len(columns))
 		if err != nil {
-			return nil, nil, fmt.Errorf("error converting to int: %w", err)
+			return nil, err
 		}
-		rightValue, err := strconv.Atoi(split[1])
-		if err != nil {
-			return nil, nil, fmt.Errorf("error converting to int: %w", err)
+		if columns == nil {
+			columns = make([][]int, len(values))
+		}
+		for i, value := range values {
+			columns[i] = append(columns[i], value)
 		}
-		leftSlice = append(leftSlice, leftValue)
-		rightSlice = append(rightSlice, rightValue)
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, nil, fmt.Errorf("error scanning file: %w", err)
+		return nil, fmt.Errorf("error scanning file: %w", err)
+	}
+	if columns == nil {
+		return nil, fmt.Errorf("the input has no lists")
 	}
 
-	return leftSlice, rightSlice, nil
+	return columns, nil
 }
 
-func partOne(left, right []int) (int, error) {
-	if len(left) != len(right) {
-		fmt.Printf("left and right slices are not the same length")
-		return 0, fmt.Errorf("left and right slices are not the same length")
+// parseLine parses the n-th line of the input, which must have the given
+// number of columns, or at least two for the first line where columns is 0.
+func parseLine(n int, line string, columns int) ([]int, error) {
+	fields := strings.Fields(line)
+	switch {
+	case columns == 0 && len(fields) < 2:
+		return nil, fmt.Errorf("line %d: expected at least 2 columns, got %d", n, len(fields))
+	case columns != 0 && len(fields) != columns:
+		return nil, fmt.Errorf("line %d: expected %d columns like the lines before, got %d", n, columns, len(fields))
 	}
 
-	slices.Sort(left)
-	slices.Sort(right)
+	values := make([]int, len(fields))
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("line %d, column %d: error converting to int: %w", n, i+1, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
 
-	distances := make([]float64, len(left))
-	for i := 0; i < len(left); i++ {
-		distances[i] = math.Abs(float64(left[i]) - float64(right[i]))
+// partOne pairs the IDs of both lists in sorted order and sums the distances
+// between the pairs, exactly however large the IDs.
+func partOne(left, right []int) (*intmath.Sum, error) {
+	if len(left) != len(right) {
+		return nil, fmt.Errorf("left and right lists are not the same length")
 	}
 
-	sum := 0.0
-	for _, distance := range distances {
-		sum += distance
+	left = slices.Sorted(slices.Values(left))
+	right = slices.Sorted(slices.Values(right))
+
+	sum := new(intmath.Sum)
+	for i := range left {
+		sum.AddDistance(left[i], right[i], 1)
 	}
 
-	return int(sum), nil
+	return sum, nil
 }
 
-func partTwo(left, right []int) int {
-	lookup := make(map[int]int)
-	for _, value := range right {
-		lookup[value]++
-	}
+func partTwo(left, right []int) *intmath.Sum {
+	lookup := countIDs(right)
 
-	similarity := 0
+	similarity := new(intmath.Sum)
 	for _, value := range left {
 		if lookup[value] > 0 {
-			similarity += value * lookup[value]
+			similarity.AddProduct(value, lookup[value])
+		}
+	}
+
+	return similarity
+}
+
+// countIDs returns how many times every ID appears in list.
+func countIDs(list []int) map[int]int {
+	counts := make(map[int]int)
+	for _, value := range list {
+		counts[value]++
+	}
+	return counts
+}
+
+// report describes the lists beyond the two answers. The distances and the
+// similarity contributors are about the puzzle's lists, the first two.
+type report struct {
+	// Answers is only filled in for the JSON report, the text one follows
+	// the printed answers
+	Answers      answers          `json:"answers"`
+	Lists        []listReport     `json:"lists"`
+	Distances    []distanceBucket `json:"distances"`
+	Contributors []contributor    `json:"similarity_contributors"`
+	// ContributorCount is the number of IDs in both lists, of which the top
+	// are listed
+	ContributorCount int `json:"similarity_contributor_count"`
+}
+
+type listReport struct {
+	Column   int         `json:"column"`
+	Length   int         `json:"length"`
+	Distinct int         `json:"distinct"`
+	Min      int         `json:"min"`
+	Max      int         `json:"max"`
+	Median   json.Number `json:"median"`
+	// Duplicates lists the top IDs appearing more than once, most repeated
+	// first, out of DuplicateCount
+	Duplicates     []idCount `json:"duplicates"`
+	DuplicateCount int       `json:"duplicate_count"`
+	// OnlyHere lists the smallest IDs appearing in no other list, out of
+	// OnlyHereCount
+	OnlyHere      []int `json:"only_in_this_list"`
+	OnlyHereCount int   `json:"only_in_this_list_count"`
+}
+
+type idCount struct {
+	ID    int `json:"id"`
+	Count int `json:"count"`
+}
+
+// distanceBucket counts the pair distances from Min to Max.
+type distanceBucket struct {
+	Min   uint64 `json:"min"`
+	Max   uint64 `json:"max"`
+	Count int    `json:"count"`
+}
+
+// contributor is an ID adding ID * LeftCount * RightCount to the similarity.
+type contributor struct {
+	ID           int      `json:"id"`
+	LeftCount    int      `json:"left_count"`
+	RightCount   int      `json:"right_count"`
+	Contribution *big.Int `json:"contribution"`
+	// Share is the fraction of the similarity the ID contributes
+	Share float64 `json:"share"`
+}
+
+// newReport describes the lists in columns, listing at most top IDs per
+// section, or all of them if top is 0.
+func newReport(columns [][]int, top int) report {
+	limit := func(n int) int {
+		if top <= 0 {
+			return n
+		}
+		return min(n, top)
+	}
+
+	counts := make([]map[int]int, len(columns))
+	for i, column := range columns {
+		counts[i] = countIDs(column)
+	}
+
+	var r report
+	for i, column := range columns {
+		sorted := slices.Sorted(slices.Values(column))
+		list := listReport{
+			Column:   i + 1,
+			Length:   len(sorted),
+			Distinct: len(counts[i]),
+			Min:      sorted[0],
+			Max:      sorted[len(sorted)-1],
+			Median:   median(sorted),
+		}
+
+		duplicates, onlyHere := []idCount{}, []int{}
+		for id, count := range counts[i] {
+			if count > 1 {
+				duplicates = append(duplicates, idCount{ID: id, Count: count})
+			}
+			if !inOtherList(counts, i, id) {
+				onlyHere = append(onlyHere, id)
+			}
+		}
+		slices.SortFunc(duplicates, func(a, b idCount) int {
+			return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.ID, b.ID))
+		})
+		slices.Sort(onlyHere)
+		list.Duplicates, list.DuplicateCount = duplicates[:limit(len(duplicates))], len(duplicates)
+		list.OnlyHere, list.OnlyHereCount = onlyHere[:limit(len(onlyHere))], len(onlyHere)
+
+		r.Lists = append(r.Lists, list)
+	}
+
+	r.Distances = distanceHistogram(columns[0], columns[1])
+
+	total := new(big.Int)
+	r.Contributors = []contributor{}
+	for id, leftCount := range counts[0] {
+		rightCount := counts[1][id]
+		if rightCount == 0 {
+			continue
+		}
+		contribution := big.NewInt(int64(id))
+		contribution.Mul(contribution, big.NewInt(int64(leftCount)))
+		contribution.Mul(contribution, big.NewInt(int64(rightCount)))
+		total.Add(total, contribution)
+		r.Contributors = append(r.Contributors, contributor{ID: id, LeftCount: leftCount, RightCount: rightCount, Contribution: contribution})
+	}
+	slices.SortFunc(r.Contributors, func(a, b contributor) int {
+		return cmp.Or(b.Contribution.Cmp(a.Contribution), cmp.Compare(a.ID, b.ID))
+	})
+	r.ContributorCount = len(r.Contributors)
+	r.Contributors = r.Contributors[:limit(len(r.Contributors))]
+	for i := range r.Contributors {
+		if total.Sign() != 0 {
+			r.Contributors[i].Share, _ = new(big.Rat).SetFrac(r.Contributors[i].Contribution, total).Float64()
+		}
+	}
+
+	return r
+}
+
+// inOtherList reports whether id appears in any list but the i-th.
+func inOtherList(counts []map[int]int, i, id int) bool {
+	for j, other := range counts {
+		if j != i && other[id] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// median returns the middle of sorted, or the mean of the two middle values
+// for an even length.
+func median(sorted []int) json.Number {
+	n := len(sorted)
+	if n%2 == 1 {
+		return json.Number(strconv.Itoa(sorted[n/2]))
+	}
+	sum := new(big.Int).Add(big.NewInt(int64(sorted[n/2-1])), big.NewInt(int64(sorted[n/2])))
+	mean := new(big.Rat).SetFrac(sum, big.NewInt(2))
+	if mean.IsInt() {
+		return json.Number(mean.Num().String())
+	}
+	return json.Number(mean.FloatString(1))
+}
+
+// distanceHistogram counts the distances between the sorted pairs of both
+// lists in buckets doubling in width: 0, 1, 2-3, 4-7 and so on.
+func distanceHistogram(left, right []int) []distanceBucket {
+	left = slices.Sorted(slices.Values(left))
+	right = slices.Sorted(slices.Values(right))
+
+	var counts [65]int
+	first, last := len(counts), 0
+	for i := range left {
+		a, b := max(left[i], right[i]), min(left[i], right[i])
+		// exact even when the distance overflows an int
+		bucket := bits.Len64(uint64(a) - uint64(b))
+		counts[bucket]++
+		first, last = min(first, bucket), max(last, bucket)
+	}
+
+	var buckets []distanceBucket
+	for bucket := first; bucket <= last; bucket++ {
+		b := distanceBucket{Count: counts[bucket]}
+		if bucket > 0 {
+			b.Min = 1 << (bucket - 1)
+			b.Max = b.Min<<1 - 1
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets
+}
+
+func printReport(r report, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, list := range r.Lists {
+		fmt.Printf("\nList %d: %d IDs, %d distinct, min %d, median %s, max %d\n",
+			list.Column, list.Length, list.Distinct, list.Min, list.Median, list.Max)
+
+		duplicates := make([]string, len(list.Duplicates))
+		for i, duplicate := range list.Duplicates {
+			duplicates[i] = fmt.Sprintf("%d x%d", duplicate.ID, duplicate.Count)
+		}
+		fmt.Printf("  %d duplicated%s\n", list.DuplicateCount, listed(duplicates, list.DuplicateCount))
+
+		onlyHere := make([]string, len(list.OnlyHere))
+		for i, id := range list.OnlyHere {
+			onlyHere[i] = strconv.Itoa(id)
+		}
+		fmt.Printf("  %d only in this list%s\n", list.OnlyHereCount, listed(onlyHere, list.OnlyHereCount))
+	}
+
+	fmt.Println("\nDistances between the sorted pairs of lists 1 and 2:")
+	largest := 0
+	for _, bucket := range r.Distances {
+		largest = max(largest, bucket.Count)
+	}
+	for _, bucket := range r.Distances {
+		label := strconv.FormatUint(bucket.Min, 10)
+		if bucket.Max != bucket.Min {
+			label += "-" + strconv.FormatUint(bucket.Max, 10)
+		}
+		bar := strings.Repeat("#", (bucket.Count*40+largest-1)/largest)
+		fmt.Printf("  %25s %8d  %s\n", label, bucket.Count, bar)
+	}
+
+	fmt.Printf("\nSimilarity of lists 1 and 2 by ID, %d IDs in both:\n", r.ContributorCount)
+	if r.ContributorCount == 0 {
+		return nil
+	}
+	fmt.Printf("  %12s %6s %6s %16s %7s\n", "ID", "left", "right", "contribution", "share")
+	for _, c := range r.Contributors {
+		fmt.Printf("  %12d %6d %6d %16v %6.2f%%\n", c.ID, c.LeftCount, c.RightCount, c.Contribution, 100*c.Share)
+	}
+	return nil
+}
+
+// listed formats the first items of a list of total, for a report line.
+func listed(items []string, total int) string {
+	if len(items) == 0 {
+		return ""
+	}
+	more := ""
+	if len(items) < total {
+		more = ", ..."
+	}
+	return ": " + strings.Join(items, ", ") + more
+}
+
+// solveStreaming solves both parts from the number of times each location ID
+// appears in each list, which takes memory in the number of distinct IDs
+// rather than the number of lines.
+func solveStreaming() {
+	counts, err := readCounts()
+	if err != nil {
+		fmt.Printf("error reading input: %v", err)
+		return
+	}
+
+	distance := func(i, j int) (*intmath.Sum, error) { return partOneCounts(counts[i], counts[j]) }
+	similarity := func(i, j int) (*intmath.Sum, error) { return partTwoCounts(counts[i], counts[j]), nil }
+	if err := printAnswers(len(counts), distance, similarity); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// readCounts counts the location IDs of every column.
+func readCounts() ([]map[int]int, error) {
+	file, err := os.Open("./input.txt")
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	var counts []map[int]int
+	scanner := stream.NewScanner(file)
+	for n, line := range scanner.Lines() {
+		values, err := parseLine(n, line, len(counts))
+		if err != nil {
+			return nil, err
+		}
+		if counts == nil {
+			counts = make([]map[int]int, len(values))
+			for i := range counts {
+				counts[i] = make(map[int]int)
+			}
+		}
+		for i, value := range values {
+			counts[i][value]++
 		}
 	}
 
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if counts == nil {
+		return nil, fmt.Errorf("the input has no lists")
+	}
+
+	return counts, nil
+}
+
+// partOneCounts pairs the IDs in sorted order like partOne, a run of equal IDs
+// at a time.
+func partOneCounts(left, right map[int]int) (*intmath.Sum, error) {
+	leftIDs := slices.Sorted(maps.Keys(left))
+	rightIDs := slices.Sorted(maps.Keys(right))
+
+	sum := new(intmath.Sum)
+	i, j := 0, 0
+	leftRemaining, rightRemaining := 0, 0
+	for {
+		if leftRemaining == 0 && i < len(leftIDs) {
+			leftRemaining = left[leftIDs[i]]
+			i++
+		}
+		if rightRemaining == 0 && j < len(rightIDs) {
+			rightRemaining = right[rightIDs[j]]
+			j++
+		}
+		if leftRemaining == 0 || rightRemaining == 0 {
+			break
+		}
+
+		pairs := min(leftRemaining, rightRemaining)
+		sum.AddDistance(leftIDs[i-1], rightIDs[j-1], pairs)
+		leftRemaining -= pairs
+		rightRemaining -= pairs
+	}
+
+	if leftRemaining != 0 || rightRemaining != 0 || i != len(leftIDs) || j != len(rightIDs) {
+		return nil, fmt.Errorf("left and right lists are not the same length")
+	}
+
+	return sum, nil
+}
+
+func partTwoCounts(left, right map[int]int) *intmath.Sum {
+	similarity := new(intmath.Sum)
+	for value, count := range left {
+		similarity.AddProduct(value, count, right[value])
+	}
+
 	return similarity
 }
+
+// solveExternal solves both parts from the lists sorted with an external merge
+// sort: runs of runLength IDs per list are sorted in memory and written to
+// temporary files, then merged back in sorted order while pairing the lists.
+func solveExternal() {
+	dir, err := os.MkdirTemp("", "day1-runs-")
+	if err != nil {
+		fmt.Printf("error creating run directory: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	runs, err := writeRuns(dir, *runLength)
+	if err != nil {
+		fmt.Printf("error reading input: %v", err)
+		return
+	}
+
+	distance := func(i, j int) (*intmath.Sum, error) {
+		return mergePair(runs[i], runs[j], partOneSorted)
+	}
+	similarity := func(i, j int) (*intmath.Sum, error) {
+		return mergePair(runs[i], runs[j], partTwoSorted)
+	}
+	if err := printAnswers(len(runs), distance, similarity); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// writeRuns splits every column of the input into sorted runs of at most
+// runLength IDs written to dir, and returns the run files of each column.
+func writeRuns(dir string, runLength int) ([][]string, error) {
+	if runLength < 1 {
+		return nil, fmt.Errorf("run length must be at least 1, got %d", runLength)
+	}
+
+	file, err := os.Open("./input.txt")
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	var runs [][]string
+	var columns [][]int
+	flush := func() error {
+		for i, column := range columns {
+			if len(column) == 0 {
+				continue
+			}
+			slices.Sort(column)
+			path := filepath.Join(dir, fmt.Sprintf("%d-%d", i, len(runs[i])))
+			if err := writeRun(path, column); err != nil {
+				return err
+			}
+			runs[i] = append(runs[i], path)
+			columns[i] = column[:0]
+		}
+		return nil
+	}
+
+	scanner := stream.NewScanner(file)
+	for n, line := range scanner.Lines() {
+		values, err := parseLine(n, line, len(columns))
+		if err != nil {
+			return nil, err
+		}
+		if columns == nil {
+			columns = make([][]int, len(values))
+			runs = make([][]string, len(values))
+		}
+		for i, value := range values {
+			columns[i] = append(columns[i], value)
+		}
+		if len(columns[0]) == runLength {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if columns == nil {
+		return nil, fmt.Errorf("the input has no lists")
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// writeRun writes sorted IDs to path as varints.
+func writeRun(path string, values []int) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating run: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	buffer := make([]byte, 0, binary.MaxVarintLen64)
+	for _, value := range values {
+		writer.Write(binary.AppendVarint(buffer[:0], int64(value)))
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing run: %w", err)
+	}
+	return file.Close()
+}
+
+// mergePair merges the runs of two columns and hands both sorted lists to
+// solve.
+func mergePair(leftRuns, rightRuns []string, solve func(left, right *merger) (*intmath.Sum, error)) (*intmath.Sum, error) {
+	left, err := openMerger(leftRuns)
+	if err != nil {
+		return nil, err
+	}
+	defer left.Close()
+	right, err := openMerger(rightRuns)
+	if err != nil {
+		return nil, err
+	}
+	defer right.Close()
+
+	sum, err := solve(left, right)
+	// a failed read ends a list early, report it rather than what follows
+	if readErr := cmp.Or(left.err, right.err); readErr != nil {
+		return nil, readErr
+	}
+	return sum, err
+}
+
+// partOneSorted is partOne on lists already sorted.
+func partOneSorted(left, right *merger) (*intmath.Sum, error) {
+	sum := new(intmath.Sum)
+	for {
+		a, okLeft := left.next()
+		b, okRight := right.next()
+		if okLeft != okRight {
+			return nil, fmt.Errorf("left and right lists are not the same length")
+		}
+		if !okLeft {
+			return sum, nil
+		}
+		sum.AddDistance(a, b, 1)
+	}
+}
+
+// partTwoSorted is partTwo on lists already sorted, counting every ID of both
+// lists as it walks them side by side.
+func partTwoSorted(left, right *merger) (*intmath.Sum, error) {
+	similarity := new(intmath.Sum)
+	a, okLeft := left.next()
+	b, okRight := right.next()
+	for okLeft && okRight {
+		switch {
+		case a < b:
+			a, okLeft = left.next()
+		case a > b:
+			b, okRight = right.next()
+		default:
+			value, leftCount, rightCount := a, 0, 0
+			for okLeft && a == value {
+				leftCount++
+				a, okLeft = left.next()
+			}
+			for okRight && b == value {
+				rightCount++
+				b, okRight = right.next()
+			}
+			similarity.AddProduct(value, leftCount, rightCount)
+		}
+	}
+	return similarity, nil
+}
+
+// merger yields the IDs of sorted runs in sorted order, keeping a single ID
+// per run in memory.
+type merger struct {
+	files []*os.File
+	heads runHeap
+	err   error
+}
+
+func openMerger(paths []string) (*merger, error) {
+	m := &merger{}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			m.Close()
+			return nil, fmt.Errorf("error opening run: %w", err)
+		}
+		m.files = append(m.files, file)
+
+		run := bufio.NewReader(file)
+		value, err := binary.ReadVarint(run)
+		switch {
+		case err == io.EOF:
+			// an empty run has no head
+		case err != nil:
+			// next reports nothing once err is set, and mergePair returns it
+			m.err = fmt.Errorf("error reading run: %w", err)
+		default:
+			m.heads = append(m.heads, runHead{value: int(value), run: run})
+		}
+	}
+	heap.Init(&m.heads)
+	return m, nil
+}
+
+// next returns the smallest ID left, false once the runs are exhausted or a
+// run could not be read, see err.
+func (m *merger) next() (int, bool) {
+	if len(m.heads) == 0 || m.err != nil {
+		return 0, false
+	}
+
+	head := &m.heads[0]
+	value := head.value
+	next, err := binary.ReadVarint(head.run)
+	switch {
+	case err == io.EOF:
+		heap.Pop(&m.heads)
+	case err != nil:
+		m.err = fmt.Errorf("error reading run: %w", err)
+	default:
+		head.value = int(next)
+		heap.Fix(&m.heads, 0)
+	}
+	return value, true
+}
+
+func (m *merger) Close() {
+	for _, file := range m.files {
+		file.Close()
+	}
+}
+
+// runHead is the next ID of a run.
+type runHead struct {
+	value int
+	run   *bufio.Reader
+}
+
+type runHeap []runHead
+
+func (h runHeap) Len() int           { return len(h) }
+func (h runHeap) Less(i, j int) bool { return h[i].value < h[j].value }
+func (h runHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)        { *h = append(*h, x.(runHead)) }
+
+func (h *runHeap) Pop() any {
+	old := *h
+	head := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return head
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// inDir runs the test in a temporary directory holding input as input.txt.
+func inDir(t *testing.T, input string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "input.txt"), []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// generateLists returns lines of columns random IDs, drawn from few distinct
+// values so that both lists share IDs and repeat them.
+func generateLists(random *rand.Rand, lines, columns int, ids []int) ([][]int, string) {
+	lists := make([][]int, columns)
+	var b strings.Builder
+	for range lines {
+		for i := range lists {
+			id := ids[random.IntN(len(ids))]
+			lists[i] = append(lists[i], id)
+			if i > 0 {
+				b.WriteString("   ")
+			}
+			fmt.Fprint(&b, id)
+		}
+		b.WriteByte('\n')
+	}
+	return lists, b.String()
+}
+
+// referenceAnswers computes both parts with big integers, the slow way.
+func referenceAnswers(left, right []int) (string, string) {
+	left, right = slices.Sorted(slices.Values(left)), slices.Sorted(slices.Values(right))
+	distance := new(big.Int)
+	for i := range left {
+		d := new(big.Int).Sub(big.NewInt(int64(left[i])), big.NewInt(int64(right[i])))
+		distance.Add(distance, d.Abs(d))
+	}
+
+	similarity := new(big.Int)
+	for _, a := range left {
+		for _, b := range right {
+			if a == b {
+				similarity.Add(similarity, big.NewInt(int64(a)))
+			}
+		}
+	}
+	return distance.String(), similarity.String()
+}
+
+func TestModesAgree(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 42))
+	tests := []struct {
+		name    string
+		lines   int
+		columns int
+		ids     []int
+	}{
+		{"puzzle sized IDs", 200, 2, []int{10000, 23456, 34567, 45678, 56789, 67890, 78901, 89012, 99999}},
+		{"one line", 1, 2, []int{3, 4}},
+		{"single ID", 50, 2, []int{7}},
+		{"negative and extreme IDs", 120, 2, []int{math.MinInt, -5, 0, 5, math.MaxInt}},
+		{"more columns", 80, 4, []int{1, 2, 3, 5, 8, 13}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lists, input := generateLists(random, test.lines, test.columns, test.ids)
+			inDir(t, input)
+
+			columns, err := readInput()
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts, err := readCounts()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := range lists {
+				for j := range lists {
+					if i == j {
+						continue
+					}
+					wantDistance, wantSimilarity := referenceAnswers(lists[i], lists[j])
+					check := func(mode string, distance, similarity fmt.Stringer, err error) {
+						t.Helper()
+						if err != nil {
+							t.Fatalf("%s, columns %d and %d: %v", mode, i+1, j+1, err)
+						}
+						if distance.String() != wantDistance || similarity.String() != wantSimilarity {
+							t.Errorf("%s, columns %d and %d: got %v and %v, want %s and %s",
+								mode, i+1, j+1, distance, similarity, wantDistance, wantSimilarity)
+						}
+					}
+
+					distance, err := partOne(columns[i], columns[j])
+					check("in memory", distance, partTwo(columns[i], columns[j]), err)
+
+					distance, err = partOneCounts(counts[i], counts[j])
+					check("stream", distance, partTwoCounts(counts[i], counts[j]), err)
+				}
+			}
+
+			// tiny runs make the external sort spill and merge many of them
+			for _, runLength := range []int{1, 2, 3, 7, test.lines + 1} {
+				runs, err := writeRuns(t.TempDir(), runLength)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := (test.lines + runLength - 1) / runLength; len(runs[0]) != want {
+					t.Fatalf("run length %d: got %d runs, want %d", runLength, len(runs[0]), want)
+				}
+				for i := range runs {
+					for j := range runs {
+						if i == j {
+							continue
+						}
+						wantDistance, wantSimilarity := referenceAnswers(lists[i], lists[j])
+						distance, err := mergePair(runs[i], runs[j], partOneSorted)
+						if err != nil {
+							t.Fatal(err)
+						}
+						similarity, err := mergePair(runs[i], runs[j], partTwoSorted)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if distance.String() != wantDistance || similarity.String() != wantSimilarity {
+							t.Errorf("external with runs of %d, columns %d and %d: got %v and %v, want %s and %s",
+								runLength, i+1, j+1, distance, similarity, wantDistance, wantSimilarity)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPartTwoCountsOverflow(t *testing.T) {
+	// the counts alone multiply past an int
+	counts := map[int]int{1: 1 << 32, 3: 1}
+	if got, want := partTwoCounts(counts, counts).String(), "18446744073709551619"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestCorruptedRun(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good")
+	if err := writeRun(good, []int{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	// a varint cut short in the middle of its first value
+	corrupted := filepath.Join(dir, "corrupted")
+	if err := os.WriteFile(corrupted, []byte{0x80}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty")
+	if err := writeRun(empty, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := mergePair([]string{good, corrupted}, []string{good, empty}, partOneSorted); err == nil {
+		t.Error("merging a corrupted run: got no error")
+	}
+	sum, err := mergePair([]string{good, empty}, []string{empty, good}, partOneSorted)
+	if err != nil || sum.String() != "0" {
+		t.Errorf("merging empty runs: got %v, %v, want 0", sum, err)
+	}
+}
+
+// captureStdout returns what f prints.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		output <- string(b)
+	}()
+	f()
+	w.Close()
+	return <-output
+}
+
+func TestReportJSON(t *testing.T) {
+	format := *reportFormat
+	*reportFormat = "json"
+	t.Cleanup(func() { *reportFormat = format })
+
+	tests := []struct {
+		name    string
+		input   string
+		answers answers
+	}{
+		{"example", "3   4\n4   3\n2   5\n1   3\n3   9\n3   3\n", answers{PartOne: "11", PartTwo: "31"}},
+		{"three columns", "1 2 3\n2 2 3\n", answers{
+			PartOne: "1", PartTwo: "4",
+			Distances:  [][]*json.Number{{nil, number("1"), number("3")}, {number("1"), nil, number("2")}, {number("3"), number("2"), nil}},
+			Similarity: [][]*json.Number{{nil, number("4"), number("0")}, {number("4"), nil, number("0")}, {number("0"), number("0"), nil}},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inDir(t, test.input)
+			output := captureStdout(t, main)
+
+			// the output is a single JSON document and nothing else
+			var r report
+			decoder := json.NewDecoder(strings.NewReader(output))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&r); err != nil {
+				t.Fatalf("decoding %q: %v", output, err)
+			}
+			if rest, _ := io.ReadAll(decoder.Buffered()); strings.TrimSpace(string(rest)) != "" {
+				t.Errorf("got %q after the JSON document", rest)
+			}
+			if !reflect.DeepEqual(r.Answers, test.answers) {
+				got, _ := json.Marshal(r.Answers)
+				want, _ := json.Marshal(test.answers)
+				t.Errorf("got answers %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func number(s string) *json.Number {
+	n := json.Number(s)
+	return &n
+}
@@ -2,34 +2,80 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
+
+	"aoc/graph"
+	"aoc/grid"
+	"aoc/visual"
 )
 
+var visualize = flag.String("visualize", "", "write the trails reachable from every trailhead as JSON to `file`")
+
 func main() {
+	flag.Parse()
+
 	tMap, err := readInput()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	nodes := mapToNodes(tMap)
-	zeroNodes := findValueNodes(nodes, 0)
+	trails := mapToGraph(tMap)
+	trailheads := findValueCoordinates(tMap, 0)
 
 	uniqueSum := 0
 	nonUniqueSum := 0
-	for _, zeroNode := range zeroNodes {
-		paths := collectPaths(zeroNode, 0)
-		uniquePaths := make(map[coordinate]bool)
-		for _, path := range paths {
-			uniquePaths[path.coordinate] = true
+	for _, trailhead := range trailheads {
+		pathCounts, err := trails.CountPaths(trailhead)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for c, count := range pathCounts {
+			if tMap[c.Y][c.X] == 9 {
+				uniqueSum++
+				nonUniqueSum += count
+			}
 		}
-		uniqueSum += len(uniquePaths)
-		nonUniqueSum += len(paths)
 	}
 
 	fmt.Printf("(Part one) Unique sum: %d\n", uniqueSum)
 	fmt.Printf("(Part two) Non-unique sum: %d\n", nonUniqueSum)
+
+	if *visualize != "" {
+		v, err := visualizeTrails(tMap, trails, trailheads)
+		if err == nil {
+			err = v.WriteFile(*visualize)
+		}
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+// visualizeTrails adds a frame per trailhead with the cells reachable from it,
+// the trails building up over the frames.
+func visualizeTrails(tMap [][]int, trails *graph.Graph[grid.Point], trailheads []grid.Point) (*visual.Visualization, error) {
+	v := visual.FromDigits("Hiking trails", tMap)
+	v.Cumulative = true
+	for _, trailhead := range trailheads {
+		pathCounts, err := trails.CountPaths(trailhead)
+		if err != nil {
+			return nil, err
+		}
+		v.AddFrame(fmt.Sprintf("trailhead %v", trailhead), trailCells(tMap, pathCounts))
+	}
+	return v, nil
+}
+
+func trailCells(tMap [][]int, pathCounts map[grid.Point]int) []visual.Cell {
+	cells := make([]visual.Cell, 0, len(pathCounts))
+	for c := range pathCounts {
+		cells = append(cells, visual.Cell{X: c.X, Y: c.Y, Color: visual.Gradient(float64(tMap[c.Y][c.X]) / 9)})
+	}
+	return cells
 }
 
 var digits = map[string]int{
@@ -45,32 +91,6 @@ var digits = map[string]int{
 	"9": 9,
 }
 
-type coordinate struct {
-	x int
-	y int
-}
-
-func (c coordinate) getAdjacent() []coordinate {
-	up := coordinate{x: c.x - 1, y: c.y}
-	down := coordinate{x: c.x + 1, y: c.y}
-	left := coordinate{x: c.x, y: c.y - 1}
-	right := coordinate{x: c.x, y: c.y + 1}
-
-	return []coordinate{up, down, left, right}
-}
-
-func (c coordinate) isWithinBounds(tMap [][]int) bool {
-	if c.x < 0 || c.x >= len(tMap) {
-		return false
-	}
-
-	if c.y < 0 || c.y >= len(tMap[c.x]) {
-		return false
-	}
-
-	return true
-}
-
 func readInput() ([][]int, error) {
 	file, err := os.Open("input.txt")
 	if err != nil {
@@ -112,69 +132,37 @@ func readInput() ([][]int, error) {
 	return tMap, nil
 }
 
-type node struct {
-	coordinate
-	value     int
-	neighbors map[int][]*node
-}
-
-func mapToNodes(tMap [][]int) [][]*node {
-	nodes := make([][]*node, 0)
+// mapToGraph connects every cell to the adjacent cells exactly one step higher.
+func mapToGraph(tMap [][]int) *graph.Graph[grid.Point] {
+	trails := graph.New[grid.Point]()
 	for y, line := range tMap {
-		nodes = append(nodes, make([]*node, 0))
 		for x, cell := range line {
-			nodes[y] = append(nodes[y], &node{
-				coordinate: coordinate{x: x, y: y},
-				value:      cell,
-				neighbors:  make(map[int][]*node),
-			})
-		}
-	}
-
-	for _, line := range nodes {
-		for _, n := range line {
-			adjacents := n.getAdjacent()
-			for _, adj := range adjacents {
-				if !adj.isWithinBounds(tMap) {
+			current := grid.Point{X: x, Y: y}
+			trails.AddVertex(current)
+			for _, adj := range current.Neighbors() {
+				if !grid.Within(adj, tMap) {
 					continue
 				}
 
-				adjNode := nodes[adj.y][adj.x]
-				n.neighbors[adjNode.value] = append(n.neighbors[adjNode.value], adjNode)
+				if tMap[adj.Y][adj.X] == cell+1 {
+					trails.AddEdge(current, adj)
+				}
 			}
 		}
 	}
 
-	return nodes
+	return trails
 }
 
-func findValueNodes(nodes [][]*node, value int) []*node {
-	valueNodes := make([]*node, 0)
-	for _, line := range nodes {
-		for _, n := range line {
-			if n.value == value {
-				valueNodes = append(valueNodes, n)
+func findValueCoordinates(tMap [][]int, value int) []grid.Point {
+	coordinates := make([]grid.Point, 0)
+	for y, line := range tMap {
+		for x, cell := range line {
+			if cell == value {
+				coordinates = append(coordinates, grid.Point{X: x, Y: y})
 			}
 		}
 	}
 
-	return valueNodes
-}
-
-func collectPaths(current *node, targetValue int) []*node {
-	if current.value == targetValue && targetValue == 9 {
-		return []*node{current}
-	}
-
-	if current.value != targetValue {
-		return nil
-	}
-
-	nextValue := targetValue + 1
-	var result []*node
-	for _, neighbor := range current.neighbors[nextValue] {
-		result = append(result, collectPaths(neighbor, nextValue)...)
-	}
-
-	return result
+	return coordinates
 }
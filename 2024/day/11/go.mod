@@ -1,3 +1,7 @@
 module day_eleven
 
 go 1.23.3
+
+require aoc v0.0.0
+
+replace aoc => ../../../aoc
@@ -1,32 +1,38 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"math/big"
 	"os"
 	"strconv"
 	"strings"
+
+	"aoc/intmath"
+	"aoc/params"
+)
+
+var (
+	forceBigInt = flag.Bool("bigint", false, "use math/big instead of overflow-checked int arithmetic")
+
+	partOneBlinks = params.Int("part-one-blinks", 25, "number of blinks in part one")
+	partTwoBlinks = params.Int("part-two-blinks", 75, "number of blinks in part two")
 )
 
 func main() {
+	if err := params.Parse(); err != nil {
+		fmt.Printf("error parsing parameters: %v", err)
+		return
+	}
+
 	stones, err := readInput()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	blinks := 25
-	sum := 0
-	for _, stone := range stones {
-		sum += countStones(stone, blinks)
-	}
-	fmt.Printf("(Part one) Stone count: %d\n", sum)
-
-	blinks = 75
-	sum = 0
-	for _, stone := range stones {
-		sum += countStones(stone, blinks)
-	}
-	fmt.Printf("(Part two) Stone count: %d\n", sum)
+	fmt.Printf("(Part one) Stone count: %s\n", countAllStones(stones, *partOneBlinks))
+	fmt.Printf("(Part two) Stone count: %s\n", countAllStones(stones, *partTwoBlinks))
 }
 
 func readInput() ([]int, error) {
@@ -47,47 +53,129 @@ func readInput() ([]int, error) {
 	return values, nil
 }
 
+// countAllStones counts the stones after the given number of blinks with ints,
+// falling back to math/big as soon as a stone or a count overflows.
+func countAllStones(stones []int, blinks int) string {
+	if !*forceBigInt {
+		sum, err := sumStones(stones, blinks)
+		if err == nil {
+			return strconv.Itoa(sum)
+		}
+		fmt.Fprintf(os.Stderr, "%v, falling back to math/big\n", err)
+	}
+
+	sum := new(big.Int)
+	for _, stone := range stones {
+		sum.Add(sum, countStonesBig(big.NewInt(int64(stone)), blinks))
+	}
+	return sum.String()
+}
+
+func sumStones(stones []int, blinks int) (int, error) {
+	sum := 0
+	for _, stone := range stones {
+		count, err := countStones(stone, blinks)
+		if err != nil {
+			return 0, err
+		}
+
+		var ok bool
+		sum, ok = intmath.CheckedAdd(sum, count)
+		if !ok {
+			return 0, fmt.Errorf("summing stone counts: %w", intmath.ErrOverflow)
+		}
+	}
+
+	return sum, nil
+}
+
 var cache = map[string]int{}
 
-func countStones(stone, blinks int) int {
+func countStones(stone, blinks int) (int, error) {
 	key := fmt.Sprintf("s%d-b%d", stone, blinks)
 
 	if hit, ok := cache[key]; ok {
-		return hit
+		return hit, nil
 	}
 
 	if blinks < 0 {
-		return 0
+		return 0, nil
 	}
 
 	if blinks == 0 {
-		return 1
+		return 1, nil
 	}
 
 	if stone == 0 {
-		result := countStones(1, blinks-1)
+		result, err := countStones(1, blinks-1)
+		if err != nil {
+			return 0, err
+		}
 		cache[key] = result
-		return result
+		return result, nil
 	}
 
-	stoneAsString := strconv.Itoa(stone)
-	if len(stoneAsString)%2 == 0 {
-		left := stoneAsString[:len(stoneAsString)/2]
-		right := stoneAsString[len(stoneAsString)/2:]
-		leftAsInt, err := strconv.Atoi(left)
+	if left, right, ok := intmath.Halve(stone); ok {
+		leftCount, err := countStones(left, blinks-1)
 		if err != nil {
-			panic(err)
+			return 0, err
 		}
-		rightAsInt, err := strconv.Atoi(right)
+		rightCount, err := countStones(right, blinks-1)
 		if err != nil {
-			panic(err)
+			return 0, err
+		}
+		result, ok := intmath.CheckedAdd(leftCount, rightCount)
+		if !ok {
+			return 0, fmt.Errorf("count of stone %d after %d blinks: %w", stone, blinks, intmath.ErrOverflow)
 		}
-		result := countStones(leftAsInt, blinks-1) + countStones(rightAsInt, blinks-1)
 		cache[key] = result
-		return result
+		return result, nil
 	}
 
-	result := countStones(stone*2024, blinks-1)
+	next, ok := intmath.CheckedMul(stone, 2024)
+	if !ok {
+		return 0, fmt.Errorf("stone %d * 2024: %w", stone, intmath.ErrOverflow)
+	}
+	result, err := countStones(next, blinks-1)
+	if err != nil {
+		return 0, err
+	}
 	cache[key] = result
+	return result, nil
+}
+
+var bigCache = map[string]*big.Int{}
+
+// countStonesBig is countStones with math/big for both stone values and
+// counts. Cached results are shared, so they must never be modified.
+func countStonesBig(stone *big.Int, blinks int) *big.Int {
+	key := fmt.Sprintf("s%s-b%d", stone, blinks)
+
+	if hit, ok := bigCache[key]; ok {
+		return hit
+	}
+
+	if blinks < 0 {
+		return new(big.Int)
+	}
+
+	if blinks == 0 {
+		return big.NewInt(1)
+	}
+
+	var result *big.Int
+	stoneAsString := stone.String()
+	switch {
+	case stone.Sign() == 0:
+		result = countStonesBig(big.NewInt(1), blinks-1)
+	case len(stoneAsString)%2 == 0:
+		left, _ := new(big.Int).SetString(stoneAsString[:len(stoneAsString)/2], 10)
+		right, _ := new(big.Int).SetString(stoneAsString[len(stoneAsString)/2:], 10)
+		result = new(big.Int).Add(countStonesBig(left, blinks-1), countStonesBig(right, blinks-1))
+	default:
+		result = countStonesBig(new(big.Int).Mul(stone, big.NewInt(2024)), blinks-1)
+	}
+
+	bigCache[key] = result
 	return result
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"aoc/intmath"
+)
+
+// The expected counts come from main.py, whose Python ints never overflow.
+func TestCountAllStones(t *testing.T) {
+	example := []int{125, 17}
+	tests := []struct {
+		blinks   int
+		overflow bool
+		want     string
+	}{
+		{6, false, "22"},
+		{25, false, "55312"},
+		{75, false, "65601038650482"},
+		// far past int64, so the count falls back to math/big
+		{200, true, "3228697720950807773236428359413636851"},
+	}
+
+	for _, test := range tests {
+		_, err := sumStones(example, test.blinks)
+		if overflowed := errors.Is(err, intmath.ErrOverflow); overflowed != test.overflow {
+			t.Errorf("%d blinks: int overflow %t, want %t (%v)", test.blinks, overflowed, test.overflow, err)
+		}
+		if got := countAllStones(example, test.blinks); got != test.want {
+			t.Errorf("%d blinks: got %s, want %s", test.blinks, got, test.want)
+		}
+	}
+}
+
+func TestCountStonesBigMatchesInt(t *testing.T) {
+	*forceBigInt = true
+	defer func() { *forceBigInt = false }()
+
+	for _, stones := range [][]int{{0}, {1}, {125, 17}, {2024, 99, 1000}} {
+		want, err := sumStones(stones, 40)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := countAllStones(stones, 40); got != strconv.Itoa(want) {
+			t.Errorf("%v: math/big got %s, int got %d", stones, got, want)
+		}
+	}
+}
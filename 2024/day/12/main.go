@@ -2,12 +2,21 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"slices"
+
+	"aoc/graph"
+	"aoc/grid"
+	"aoc/visual"
 )
 
+var visualize = flag.String("visualize", "", "write the garden regions as JSON to `file`")
+
 func main() {
+	flag.Parse()
+
 	garden, err := readInput()
 	if err != nil {
 		fmt.Printf("error reading input: %v", err)
@@ -15,25 +24,44 @@ func main() {
 	}
 
 	plots := mapToPlots(garden)
-	regions := groupPlots(plots)
+	regions := groupPlots(garden, plots)
 
 	totalPrice := 0
-	for _, regions := range regions {
-		for _, region := range regions {
-			totalPrice += getRegionPrice(region)
-		}
+	for _, region := range regions {
+		totalPrice += getRegionPrice(plots, region)
 	}
 
 	fmt.Printf("(Part one) Total price: %d\n", totalPrice)
 
 	discountedPrice := 0
-	for _, regions := range regions {
-		for _, region := range regions {
-			discountedPrice += getDiscountedRegionPrice(garden, region)
-		}
+	for _, region := range regions {
+		discountedPrice += getDiscountedRegionPrice(garden, plots, region)
 	}
 
 	fmt.Printf("(Part two) Discounted price: %d\n", discountedPrice)
+
+	if *visualize != "" {
+		if err := visualizeRegions(garden, plots, regions).WriteFile(*visualize); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+func visualizeRegions(garden [][]rune, plots *graph.Graph[grid.Point], regions []region) *visual.Visualization {
+	v := visual.FromRunes("Garden regions", garden)
+	v.Cumulative = true
+
+	for i, region := range regions {
+		cells := make([]visual.Cell, len(region.plots))
+		for j, plot := range region.plots {
+			cells[j] = visual.Cell{X: plot.X, Y: plot.Y, Color: visual.Color(i)}
+		}
+		label := fmt.Sprintf("region %c: price %d, discounted %d",
+			region.value, getRegionPrice(plots, region), getDiscountedRegionPrice(garden, plots, region))
+		v.AddFrame(label, cells)
+	}
+
+	return v
 }
 
 func readInput() ([][]rune, error) {
@@ -74,102 +102,49 @@ func readInput() ([][]rune, error) {
 	return text, nil
 }
 
-type coordinate struct {
-	x int
-	y int
-}
-
-func (c coordinate) getAdjacent() []coordinate {
-	up := coordinate{x: c.x - 1, y: c.y}
-	down := coordinate{x: c.x + 1, y: c.y}
-	left := coordinate{x: c.x, y: c.y - 1}
-	right := coordinate{x: c.x, y: c.y + 1}
-
-	return []coordinate{up, down, left, right}
-}
-
-func (c coordinate) isWithinBounds(garden [][]rune) bool {
-	if c.x < 0 || c.x >= len(garden) {
-		return false
-	}
-
-	if c.y < 0 || c.y >= len(garden[c.x]) {
-		return false
-	}
-
-	return true
-}
-
-type plot struct {
-	coordinate
-	value     rune
-	neighbors map[rune][]*plot
-}
-
-func mapToPlots(garden [][]rune) [][]*plot {
-	nodes := make([][]*plot, 0)
+// mapToPlots connects every plot to the adjacent plots growing the same plant.
+func mapToPlots(garden [][]rune) *graph.Graph[grid.Point] {
+	plots := graph.New[grid.Point]()
 	for y, line := range garden {
-		nodes = append(nodes, make([]*plot, 0))
 		for x, cell := range line {
-			nodes[y] = append(nodes[y], &plot{
-				coordinate: coordinate{x: x, y: y},
-				value:      cell,
-				neighbors:  make(map[rune][]*plot),
-			})
-		}
-	}
-
-	for _, line := range nodes {
-		for _, n := range line {
-			adjacents := n.getAdjacent()
-			for _, adj := range adjacents {
-				if !adj.isWithinBounds(garden) {
+			current := grid.Point{X: x, Y: y}
+			plots.AddVertex(current)
+			for _, adj := range current.Neighbors() {
+				if !grid.Within(adj, garden) {
 					continue
 				}
 
-				adjNode := nodes[adj.y][adj.x]
-				n.neighbors[adjNode.value] = append(n.neighbors[adjNode.value], adjNode)
+				if garden[adj.Y][adj.X] == cell {
+					plots.AddEdge(current, adj)
+				}
 			}
 		}
 	}
 
-	return nodes
+	return plots
 }
 
-func dfs(current *plot, visited map[*plot]bool, group *[]*plot, targetValue rune) {
-	visited[current] = true
-	*group = append(*group, current)
-
-	for _, neighbor := range current.neighbors[targetValue] {
-		if !visited[neighbor] {
-			dfs(neighbor, visited, group, targetValue)
-		}
-	}
+type region struct {
+	value rune
+	plots []grid.Point
 }
 
-func groupPlots(plots [][]*plot) map[rune][][]*plot {
-	visited := make(map[*plot]bool)
-	groups := make(map[rune][][]*plot)
-
-	for _, row := range plots {
-		for _, p := range row {
-			if !visited[p] {
-				var group []*plot
-				dfs(p, visited, &group, p.value)
-				groups[p.value] = append(groups[p.value], group)
-			}
-		}
+func groupPlots(garden [][]rune, plots *graph.Graph[grid.Point]) []region {
+	regions := make([]region, 0)
+	for _, component := range plots.Components() {
+		first := component[0]
+		regions = append(regions, region{value: garden[first.Y][first.X], plots: component})
 	}
 
-	return groups
+	return regions
 }
 
-func getRegionPrice(region []*plot) int {
-	area := len(region)
+func getRegionPrice(plots *graph.Graph[grid.Point], region region) int {
+	area := len(region.plots)
 
 	perimeter := 0
-	for _, plot := range region {
-		perimeter += 4 - len(plot.neighbors[plot.value])
+	for _, plot := range region.plots {
+		perimeter += 4 - plots.Degree(plot)
 	}
 
 	return area * perimeter
@@ -178,13 +153,13 @@ func getRegionPrice(region []*plot) int {
 // This is the worst absolute solution I could come up with
 // I hate it, but I hate the problem even more
 // This stays here unless I magically stop hating the problem and come up with a better solution.
-func getDiscountedRegionPrice(garden [][]rune, region []*plot) int {
-	area := len(region)
+func getDiscountedRegionPrice(garden [][]rune, plots *graph.Graph[grid.Point], region region) int {
+	area := len(region.plots)
 
-	sides := scanLeft(garden, region)
-	sides += scanRight(garden, region)
-	sides += scanTop(garden, region)
-	sides += scanBottom(garden, region)
+	sides := scanLeft(garden, plots, region)
+	sides += scanRight(garden, plots, region)
+	sides += scanTop(garden, plots, region)
+	sides += scanBottom(garden, plots, region)
 
 	return area * sides
 }
@@ -202,38 +177,22 @@ func countLines(line []int) int {
 	return count
 }
 
-func (c coordinate) getLeft() coordinate {
-	return coordinate{x: c.x - 1, y: c.y}
-}
-
-func (c coordinate) getRight() coordinate {
-	return coordinate{x: c.x + 1, y: c.y}
-}
-
-func (c coordinate) getUp() coordinate {
-	return coordinate{x: c.x, y: c.y - 1}
-}
-
-func (c coordinate) getDown() coordinate {
-	return coordinate{x: c.x, y: c.y + 1}
-}
-
-func scanLeft(garden [][]rune, region []*plot) int {
+func scanLeft(garden [][]rune, plots *graph.Graph[grid.Point], region region) int {
 	potentialLines := make(map[int][]int)
-	for _, plot := range region {
-		if len(plot.neighbors[plot.value]) >= 4 {
+	for _, plot := range region.plots {
+		if plots.Degree(plot) >= 4 {
 			continue
 		}
 
-		left := plot.getLeft()
-		if !left.isWithinBounds(garden) {
-			potentialLines[left.x] = append(potentialLines[left.x], left.y)
+		left := plot.Move(grid.Left)
+		if !grid.Within(left, garden) {
+			potentialLines[left.X] = append(potentialLines[left.X], left.Y)
 			continue
 		}
 
-		leftPlot := garden[left.y][left.x]
-		if leftPlot != plot.value {
-			potentialLines[left.x] = append(potentialLines[left.x], left.y)
+		leftPlot := garden[left.Y][left.X]
+		if leftPlot != region.value {
+			potentialLines[left.X] = append(potentialLines[left.X], left.Y)
 		}
 	}
 
@@ -245,22 +204,22 @@ func scanLeft(garden [][]rune, region []*plot) int {
 	return lines
 }
 
-func scanRight(garden [][]rune, region []*plot) int {
+func scanRight(garden [][]rune, plots *graph.Graph[grid.Point], region region) int {
 	potentialLines := make(map[int][]int)
-	for _, plot := range region {
-		if len(plot.neighbors[plot.value]) >= 4 {
+	for _, plot := range region.plots {
+		if plots.Degree(plot) >= 4 {
 			continue
 		}
 
-		right := plot.getRight()
-		if !right.isWithinBounds(garden) {
-			potentialLines[right.x] = append(potentialLines[right.x], right.y)
+		right := plot.Move(grid.Right)
+		if !grid.Within(right, garden) {
+			potentialLines[right.X] = append(potentialLines[right.X], right.Y)
 			continue
 		}
 
-		rightPlot := garden[right.y][right.x]
-		if rightPlot != plot.value {
-			potentialLines[right.x] = append(potentialLines[right.x], right.y)
+		rightPlot := garden[right.Y][right.X]
+		if rightPlot != region.value {
+			potentialLines[right.X] = append(potentialLines[right.X], right.Y)
 		}
 	}
 
@@ -272,22 +231,22 @@ func scanRight(garden [][]rune, region []*plot) int {
 	return lines
 }
 
-func scanTop(garden [][]rune, region []*plot) int {
+func scanTop(garden [][]rune, plots *graph.Graph[grid.Point], region region) int {
 	potentialLines := make(map[int][]int)
-	for _, plot := range region {
-		if len(plot.neighbors[plot.value]) >= 4 {
+	for _, plot := range region.plots {
+		if plots.Degree(plot) >= 4 {
 			continue
 		}
 
-		top := plot.getUp()
-		if !top.isWithinBounds(garden) {
-			potentialLines[top.y] = append(potentialLines[top.y], top.x)
+		top := plot.Move(grid.Up)
+		if !grid.Within(top, garden) {
+			potentialLines[top.Y] = append(potentialLines[top.Y], top.X)
 			continue
 		}
 
-		topPlot := garden[top.y][top.x]
-		if topPlot != plot.value {
-			potentialLines[top.y] = append(potentialLines[top.y], top.x)
+		topPlot := garden[top.Y][top.X]
+		if topPlot != region.value {
+			potentialLines[top.Y] = append(potentialLines[top.Y], top.X)
 		}
 	}
 
@@ -299,22 +258,22 @@ func scanTop(garden [][]rune, region []*plot) int {
 	return lines
 }
 
-func scanBottom(garden [][]rune, region []*plot) int {
+func scanBottom(garden [][]rune, plots *graph.Graph[grid.Point], region region) int {
 	potentialLines := make(map[int][]int)
-	for _, plot := range region {
-		if len(plot.neighbors[plot.value]) >= 4 {
+	for _, plot := range region.plots {
+		if plots.Degree(plot) >= 4 {
 			continue
 		}
 
-		bottom := plot.getDown()
-		if !bottom.isWithinBounds(garden) {
-			potentialLines[bottom.y] = append(potentialLines[bottom.y], bottom.x)
+		bottom := plot.Move(grid.Down)
+		if !grid.Within(bottom, garden) {
+			potentialLines[bottom.Y] = append(potentialLines[bottom.Y], bottom.X)
 			continue
 		}
 
-		bottomPlot := garden[bottom.y][bottom.x]
-		if bottomPlot != plot.value {
-			potentialLines[bottom.y] = append(potentialLines[bottom.y], bottom.x)
+		bottomPlot := garden[bottom.Y][bottom.X]
+		if bottomPlot != region.value {
+			potentialLines[bottom.Y] = append(potentialLines[bottom.Y], bottom.X)
 		}
 	}
 
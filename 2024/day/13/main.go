@@ -2,51 +2,45 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
-	"math"
+	"math/big"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+
+	"aoc/grid"
+	"aoc/intmath"
+	"aoc/params"
 )
 
 const tenBillion int = 10000000000000
 
+var (
+	forceBigInt = flag.Bool("bigint", false, "use math/big instead of overflow-checked int arithmetic")
+
+	offset = params.Int("offset", tenBillion, "distance the prizes are moved on both axes in part two")
+)
+
 func main() {
+	if err := params.Parse(); err != nil {
+		fmt.Printf("error parsing parameters: %v", err)
+		return
+	}
+
 	configs, err := readInput()
 	if err != nil {
 		fmt.Println(err)
 	}
 
-	totalCost := 0
-	for _, config := range configs {
-		winner, err := getPressesToPrize(config)
-		if err != nil {
-			continue
-		}
-		totalCost += getTokenCost(winner)
-	}
-
-	fmt.Printf("(Part one) Total cost: %d\n", totalCost)
-
-	totalCost = 0
-	for _, config := range configs {
-		newConfig := machineConfig{config.a, config.b, coordinate{config.prize.x + tenBillion, config.prize.y + tenBillion}}
-		winner, err := getPressesToPrize(newConfig)
-		if err != nil {
-			continue
-		}
-		totalCost += getTokenCost(winner)
-	}
-	fmt.Printf("(Part two) Total cost: %d\n", totalCost)
-}
-
-type coordinate struct {
-	x, y int
+	fmt.Printf("(Part one) Total cost: %s\n", getTotalCost(configs, 0))
+	fmt.Printf("(Part two) Total cost: %s\n", getTotalCost(configs, *offset))
 }
 
 type machineConfig struct {
-	a, b, prize coordinate
+	a, b, prize grid.Point
 }
 
 func readInput() ([]machineConfig, error) {
@@ -94,7 +88,7 @@ func readInput() ([]machineConfig, error) {
 		if err != nil {
 			return nil, err
 		}
-		aButton := coordinate{ax, ay}
+		aButton := grid.Point{X: ax, Y: ay}
 
 		b = strings.ReplaceAll(b, "Button B: ", "")
 		b = strings.Trim(b, "\n")
@@ -108,7 +102,7 @@ func readInput() ([]machineConfig, error) {
 		if err != nil {
 			return nil, err
 		}
-		bButton := coordinate{bx, by}
+		bButton := grid.Point{X: bx, Y: by}
 
 		prize = strings.ReplaceAll(prize, "Prize: ", "")
 		prize = strings.Trim(prize, "\n")
@@ -122,7 +116,7 @@ func readInput() ([]machineConfig, error) {
 		if err != nil {
 			return nil, err
 		}
-		prizeLocation := coordinate{px, py}
+		prizeLocation := grid.Point{X: px, Y: py}
 
 		machineConfigs = append(machineConfigs, machineConfig{aButton, bButton, prizeLocation})
 	}
@@ -130,54 +124,118 @@ func readInput() ([]machineConfig, error) {
 	return machineConfigs, nil
 }
 
-func getTokenCost(winner coordinate) int {
-	return winner.x*3 + winner.y
-}
+// getTotalCost sums the token cost of every winnable machine with its prize
+// moved by offset on both axes. It computes with ints and falls back to
+// math/big as soon as an intermediate value overflows.
+func getTotalCost(configs []machineConfig, offset int) string {
+	if !*forceBigInt {
+		totalCost, err := getTotalCostInt(configs, offset)
+		if err == nil {
+			return strconv.Itoa(totalCost)
+		}
+		fmt.Fprintf(os.Stderr, "%v, falling back to math/big\n", err)
+	}
 
-func isIntegral(val float64) bool {
-	return val == float64(int(val))
+	return getTotalCostBig(configs, offset).String()
 }
 
-func getY(config machineConfig) float64 {
-	by := float64(config.b.y)
-	ax := float64(config.a.x)
-	ay := float64(config.a.y)
-	bx := float64(config.b.x)
-	py := float64(config.prize.y)
-	px := float64(config.prize.x)
+func getTotalCostInt(configs []machineConfig, offset int) (int, error) {
+	totalCost := 0
+	for _, config := range configs {
+		px, okX := intmath.CheckedAdd(config.prize.X, offset)
+		py, okY := intmath.CheckedAdd(config.prize.Y, offset)
+		if !okX || !okY {
+			return 0, fmt.Errorf("moving prize %v by %d: %w", config.prize, offset, intmath.ErrOverflow)
+		}
+
+		winner, err := getPressesToPrize(machineConfig{config.a, config.b, grid.Point{X: px, Y: py}})
+		if errors.Is(err, intmath.ErrOverflow) {
+			return 0, fmt.Errorf("solving %v: %w", config, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		cost, ok := getTokenCost(winner)
+		if !ok {
+			return 0, fmt.Errorf("token cost of %v: %w", winner, intmath.ErrOverflow)
+		}
+		totalCost, ok = intmath.CheckedAdd(totalCost, cost)
+		if !ok {
+			return 0, fmt.Errorf("total cost: %w", intmath.ErrOverflow)
+		}
+	}
 
-	y := (ay*px - ax*py) / (bx*ay - ax*by)
+	return totalCost, nil
+}
 
-	if !isIntegral(y) {
-		return math.NaN()
+func getTokenCost(winner grid.Point) (int, bool) {
+	cost, ok := intmath.CheckedMul(winner.X, 3)
+	if !ok {
+		return 0, false
 	}
-	return y
+	return intmath.CheckedAdd(cost, winner.Y)
 }
 
-func getX(config machineConfig, y float64) float64 {
-	ax := float64(config.a.x)
-	bx := float64(config.b.x)
-	px := float64(config.prize.x)
+// getPressesToPrize solves
+//
+//	x*a.X + y*b.X = prize.X
+//	x*a.Y + y*b.Y = prize.Y
+//
+// for the number of A presses x and B presses y.
+func getPressesToPrize(config machineConfig) (grid.Point, error) {
+	x, y, err := intmath.SolveInt2(
+		config.a.X, config.b.X,
+		config.a.Y, config.b.Y,
+		config.prize.X, config.prize.Y,
+	)
+	if err != nil {
+		return grid.Point{}, err
+	}
 
-	x := (px - bx*y) / ax
+	return grid.Point{X: x, Y: y}, nil
+}
 
-	if !isIntegral(x) {
-		return math.NaN()
+func getTotalCostBig(configs []machineConfig, offset int) *big.Int {
+	totalCost := new(big.Int)
+	bigOffset := big.NewInt(int64(offset))
+	three := big.NewInt(3)
+	for _, config := range configs {
+		x, y, ok := getPressesToPrizeBig(config, bigOffset)
+		if !ok {
+			continue
+		}
+		totalCost.Add(totalCost, x.Mul(x, three))
+		totalCost.Add(totalCost, y)
 	}
 
-	return x
+	return totalCost
 }
 
-func getPressesToPrize(config machineConfig) (coordinate, error) {
-	y := getY(config)
-	if math.IsNaN(y) {
-		return coordinate{}, fmt.Errorf("y is not an integer")
+// getPressesToPrizeBig is getPressesToPrize with math/big, returning false when
+// there is no unique integer solution.
+func getPressesToPrizeBig(config machineConfig, offset *big.Int) (x, y *big.Int, ok bool) {
+	ax, ay := big.NewInt(int64(config.a.X)), big.NewInt(int64(config.a.Y))
+	bx, by := big.NewInt(int64(config.b.X)), big.NewInt(int64(config.b.Y))
+	px := new(big.Int).Add(big.NewInt(int64(config.prize.X)), offset)
+	py := new(big.Int).Add(big.NewInt(int64(config.prize.Y)), offset)
+
+	det := bigCross(ax, bx, ay, by)
+	if det.Sign() == 0 {
+		return nil, nil, false
 	}
 
-	x := getX(config, y)
-	if math.IsNaN(x) {
-		return coordinate{}, fmt.Errorf("x is not an integer")
+	x, xRemainder := new(big.Int).QuoRem(bigCross(px, bx, py, by), det, new(big.Int))
+	y, yRemainder := new(big.Int).QuoRem(bigCross(ax, px, ay, py), det, new(big.Int))
+	if xRemainder.Sign() != 0 || yRemainder.Sign() != 0 {
+		return nil, nil, false
 	}
 
-	return coordinate{int(x), int(y)}, nil
+	return x, y, true
+}
+
+// bigCross returns a*d - b*c.
+func bigCross(a, b, c, d *big.Int) *big.Int {
+	ad := new(big.Int).Mul(a, d)
+	return ad.Sub(ad, new(big.Int).Mul(b, c))
 }
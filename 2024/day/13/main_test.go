@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"aoc/grid"
+	"aoc/intmath"
+)
+
+// exampleMachines are the machines of the puzzle's example, and a last one
+// built to be won with about 1.6e18 and 8e17 presses once its prize is moved
+// by 4e18.
+var exampleMachines = []machineConfig{
+	{a: grid.Point{X: 94, Y: 34}, b: grid.Point{X: 22, Y: 67}, prize: grid.Point{X: 8400, Y: 5400}},
+	{a: grid.Point{X: 26, Y: 66}, b: grid.Point{X: 67, Y: 21}, prize: grid.Point{X: 12748, Y: 12176}},
+	{a: grid.Point{X: 17, Y: 86}, b: grid.Point{X: 84, Y: 37}, prize: grid.Point{X: 7870, Y: 6450}},
+	{a: grid.Point{X: 69, Y: 23}, b: grid.Point{X: 27, Y: 71}, prize: grid.Point{X: 18641, Y: 10279}},
+	{a: grid.Point{X: 2, Y: 1}, b: grid.Point{X: 1, Y: 3}, prize: grid.Point{X: 17, Y: 16}},
+}
+
+// The expected costs were computed with Python's unbounded ints.
+func TestGetTotalCost(t *testing.T) {
+	tests := []struct {
+		offset   int
+		overflow bool
+		want     string
+	}{
+		{0, false, "504"},
+		{tenBillion, false, "14875318608932"},
+		// the cross products of Cramer's rule overflow, so the cost falls
+		// back to math/big
+		{4_000_000_000_000_000_000, true, "5600000000000000024"},
+	}
+
+	for _, test := range tests {
+		_, err := getTotalCostInt(exampleMachines, test.offset)
+		if overflowed := errors.Is(err, intmath.ErrOverflow); overflowed != test.overflow {
+			t.Errorf("offset %d: int overflow %t, want %t (%v)", test.offset, overflowed, test.overflow, err)
+		}
+		if got := getTotalCost(exampleMachines, test.offset); got != test.want {
+			t.Errorf("offset %d: got %s, want %s", test.offset, got, test.want)
+		}
+		if got := getTotalCostBig(exampleMachines, test.offset).String(); got != test.want {
+			t.Errorf("offset %d: math/big got %s, want %s", test.offset, got, test.want)
+		}
+	}
+}
@@ -1,3 +1,7 @@
 module day_fourteen
 
-go 1.23.3
\ No newline at end of file
+go 1.23.3
+
+require aoc v0.0.0
+
+replace aoc => ../../../aoc
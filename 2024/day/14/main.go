@@ -2,24 +2,45 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
-	"math"
 	"os"
 	"slices"
 	"strconv"
 	"strings"
+
+	"aoc/grid"
+	"aoc/intmath"
+	"aoc/params"
+	"aoc/visual"
+)
+
+var (
+	width       = params.Int("width", 101, "width of the space the robots move in")
+	height      = params.Int("height", 103, "height of the space the robots move in")
+	seconds     = params.Int("seconds", 100, "seconds the robots move in part one")
+	minLineSize = params.Int("min-line", 10, "robots in a vertical line that make up the picture in part two")
+	framesFrom  = params.Int("frames-from", -1, "first second shown by -visualize, -1 centers the frames on the picture")
+	frameCount  = params.Int("frames", 101, "number of seconds shown by -visualize")
+
+	visualize = flag.String("visualize", "", "write the robot positions second by second as JSON to `file`")
 )
 
 func main() {
+	if err := params.Parse(); err != nil {
+		fmt.Println(err)
+		return
+	}
+
 	robots, err := readInput()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	movementTime := 100
-	xBound := 101
-	yBound := 103
+	movementTime := *seconds
+	xBound := *width
+	yBound := *height
 
 	for j := 0; j < len(robots); j++ {
 		robots[j].move(movementTime)
@@ -41,27 +62,54 @@ func main() {
 	} else {
 		fmt.Printf("(Part two) Vertical line found at time: %d\n", verticalLineTime)
 	}
+
+	if *visualize != "" {
+		from := *framesFrom
+		if from < 0 {
+			from = max(0, verticalLineTime-*frameCount/2)
+		}
+		if err := visualizeRobots(robots, xBound, yBound, from, *frameCount).WriteFile(*visualize); err != nil {
+			fmt.Println(err)
+		}
+	}
 }
 
-type coordinate struct {
-	x int
-	y int
+func visualizeRobots(robots []robot, xBound, yBound, from, count int) *visual.Visualization {
+	v := visual.Empty("Robots", xBound, yBound)
+
+	robotsCopy := make([]robot, len(robots))
+	copy(robotsCopy, robots)
+	for j := range robotsCopy {
+		robotsCopy[j].reset()
+		robotsCopy[j].move(from)
+		robotsCopy[j].wrap(xBound, yBound)
+	}
+
+	for second := from; second < from+count; second++ {
+		cells := make([]visual.Cell, len(robotsCopy))
+		for j := range robotsCopy {
+			cells[j] = visual.Cell{X: robotsCopy[j].currentPosition.X, Y: robotsCopy[j].currentPosition.Y, Color: "green"}
+			robotsCopy[j].move(1)
+			robotsCopy[j].wrap(xBound, yBound)
+		}
+		v.AddFrame(fmt.Sprintf("second %d", second), cells)
+	}
+
+	return v
 }
 
 type robot struct {
-	startingPosition coordinate
-	velocity         coordinate
-	currentPosition  coordinate
+	startingPosition grid.Point
+	velocity         grid.Point
+	currentPosition  grid.Point
 }
 
 func (r *robot) move(seconds int) {
-	r.currentPosition.x += r.velocity.x * seconds
-	r.currentPosition.y += r.velocity.y * seconds
+	r.currentPosition = r.currentPosition.Add(r.velocity.Scale(seconds))
 }
 
 func (r *robot) wrap(xBound, yBound int) {
-	r.currentPosition.x = ((r.currentPosition.x % xBound) + xBound) % xBound
-	r.currentPosition.y = ((r.currentPosition.y % yBound) + yBound) % yBound
+	r.currentPosition = r.currentPosition.Wrap(xBound, yBound)
 }
 
 func (r *robot) reset() {
@@ -102,9 +150,9 @@ func readInput() ([]robot, error) {
 		}
 
 		robots = append(robots, robot{
-			startingPosition: coordinate{x: px, y: py},
-			velocity:         coordinate{x: vx, y: vy},
-			currentPosition:  coordinate{x: px, y: py},
+			startingPosition: grid.Point{X: px, Y: py},
+			velocity:         grid.Point{X: vx, Y: vy},
+			currentPosition:  grid.Point{X: px, Y: py},
 		})
 	}
 
@@ -133,17 +181,17 @@ func constructSafetyMap(robots []robot, xBound, yBound int) safetyMap {
 		r := &robots[i]
 
 		// ignore elements exactly on the boundary (only for even bounds)
-		if xBound%2 == 0 && r.currentPosition.x == midX {
+		if xBound%2 == 0 && r.currentPosition.X == midX {
 			continue
 		}
 		switch {
-		case r.currentPosition.x < midX && r.currentPosition.y < midY:
+		case r.currentPosition.X < midX && r.currentPosition.Y < midY:
 			q1 = append(q1, r)
-		case r.currentPosition.x > midX && r.currentPosition.y < midY:
+		case r.currentPosition.X > midX && r.currentPosition.Y < midY:
 			q2 = append(q2, r)
-		case r.currentPosition.x < midX && r.currentPosition.y > midY:
+		case r.currentPosition.X < midX && r.currentPosition.Y > midY:
 			q3 = append(q3, r)
-		case r.currentPosition.x > midX && r.currentPosition.y > midY:
+		case r.currentPosition.X > midX && r.currentPosition.Y > midY:
 			q4 = append(q4, r)
 		}
 	}
@@ -161,29 +209,30 @@ func (s *safetyMap) getSafetyFactor() int {
 }
 
 func drawRobots(robots []robot, xBound, yBound int) {
-	grid := make([][]rune, yBound)
+	picture := make([][]rune, yBound)
 	for i := 0; i < yBound; i++ {
-		grid[i] = make([]rune, xBound)
+		picture[i] = make([]rune, xBound)
 		for j := 0; j < xBound; j++ {
-			grid[i][j] = '.'
+			picture[i][j] = '.'
 		}
 	}
 
 	for i := 0; i < len(robots); i++ {
 		r := &robots[i]
-		grid[r.currentPosition.y][r.currentPosition.x] = '#'
+		picture[r.currentPosition.Y][r.currentPosition.X] = '#'
 	}
 
 	for i := 0; i < yBound; i++ {
 		for j := 0; j < xBound; j++ {
-			fmt.Printf("%c", grid[i][j])
+			fmt.Printf("%c", picture[i][j])
 		}
 		fmt.Println()
 	}
 }
 
 func findVerticalLineTime(robots []robot, xBound, yBound int) int {
-	maxTime := math.MaxInt32
+	// positions repeat after lcm(xBound, yBound) seconds, there's no point in looking further
+	maxTime := intmath.LCM(xBound, yBound)
 	for i := range maxTime {
 		for j := 0; j < len(robots); j++ {
 			robots[j].move(1)
@@ -199,11 +248,10 @@ func findVerticalLineTime(robots []robot, xBound, yBound int) int {
 }
 
 func isVerticalLine(robots []robot) bool {
-	minLineSize := 10
 	columns := make(map[int][]int)
 
 	for _, r := range robots {
-		columns[r.currentPosition.x] = append(columns[r.currentPosition.x], r.currentPosition.y)
+		columns[r.currentPosition.X] = append(columns[r.currentPosition.X], r.currentPosition.Y)
 	}
 
 	for _, yCoords := range columns {
@@ -213,7 +261,7 @@ func isVerticalLine(robots []robot) bool {
 		for i := 1; i < len(yCoords); i++ {
 			if yCoords[i]-yCoords[i-1] <= 1 {
 				count++
-				if count >= minLineSize {
+				if count >= *minLineSize {
 					return true
 				}
 			} else {
@@ -2,14 +2,101 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"math"
 	"os"
 	"regexp"
 	"slices"
 	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"aoc/intmath"
+	"aoc/params"
+	"aoc/stream"
+)
+
+var (
+	streamInput = flag.Bool("stream", false, "check the reports line by line instead of loading them, for inputs too large for memory")
+	bruteForce  = flag.Bool("brute-force", false, "check part two by removing every combination of levels, to cross-check the dynamic programming")
+	explain     = flag.String("explain", "", "also list the unsafe reports, why they are and which levels to remove, as a `text` table or json")
+
+	tolerance = params.Int("tolerance", 1, "levels that may be removed from a report in part two")
+
+	minStep          = params.Int("min-step", 1, "smallest difference allowed between adjacent levels")
+	maxStep          = params.Int("max-step", 3, "largest difference allowed between adjacent levels")
+	strict           = params.Bool("strict", true, "reject equal adjacent levels, even with a min-step of 0")
+	directionChanges = params.Int("direction-changes", 0, "times the levels may turn from increasing to decreasing or back")
+	maxSpan          = params.Int("max-span", 0, "largest difference allowed between the lowest and highest level of a report, 0 for no limit")
 )
 
+// rules say which reports are safe. The puzzle's are steps of 1 to 3 levels
+// all in the same direction, and they can be changed with -param or the params
+// of aoc.json to evaluate variants of the puzzle.
+type rules struct {
+	// minStep and maxStep bound the difference between adjacent levels.
+	minStep, maxStep int
+	// strict rejects equal adjacent levels, which otherwise keep the direction
+	// of the levels before them.
+	strict bool
+	// directionChanges is how many times the levels may turn from increasing
+	// to decreasing or back.
+	directionChanges int
+	// maxSpan bounds the difference between the lowest and highest level, 0
+	// for no bound.
+	maxSpan int
+}
+
+func loadRules() (rules, error) {
+	r := rules{
+		minStep:          *minStep,
+		maxStep:          *maxStep,
+		strict:           *strict,
+		directionChanges: *directionChanges,
+		maxSpan:          *maxSpan,
+	}
+	if r.minStep < 0 || r.maxStep < r.minStep {
+		return r, fmt.Errorf("invalid step range %d to %d", r.minStep, r.maxStep)
+	}
+	if r.directionChanges < 0 {
+		return r, fmt.Errorf("invalid direction changes %d, expected at least 0", r.directionChanges)
+	}
+	if r.maxSpan < 0 {
+		return r, fmt.Errorf("invalid max span %d, expected at least 0", r.maxSpan)
+	}
+	return r, nil
+}
+
 func main() {
+	if err := params.Parse(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if *tolerance < 0 {
+		fmt.Printf("invalid tolerance %d, expected at least 0\n", *tolerance)
+		return
+	}
+	rules, err := loadRules()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	switch {
+	case *explain != "" && *explain != "text" && *explain != "json":
+		fmt.Printf("invalid -explain %q, expected text or json\n", *explain)
+		return
+	case *explain != "" && *streamInput:
+		fmt.Println("-explain needs the reports in memory and can't be combined with -stream")
+		return
+	}
+
+	if *streamInput {
+		solveStreaming(rules)
+		return
+	}
+
 	reports, err := readInput()
 	if err != nil {
 		fmt.Printf("error reading input: %v", err)
@@ -18,12 +105,18 @@ func main() {
 
 	solution := solution{reports: reports}
 
-	validCount := solution.solve(isValid)
+	validCount := solution.solve(rules.isValid)
 
 	fmt.Printf("(Part one) valid count: %v\n", validCount)
 
-	validCount = solution.solve(isValidWithTolerance)
+	validCount = solution.solve(rules.toleranceCheck())
 	fmt.Printf("(Part two) valid count: %v\n", validCount)
+
+	if *explain != "" {
+		if err := printExplanations(rules.explain(reports, *tolerance), *explain); err != nil {
+			fmt.Printf("error printing explanations: %v", err)
+		}
+	}
 }
 
 func readInput() (reports [][]int, err error) {
@@ -71,34 +164,205 @@ func (p solution) solve(isValidFunc func([]int) bool) int {
 	return counter
 }
 
-func isValid(report []int) bool {
-	safeIncreases := []int{-3, -2, -1, 1, 2, 3}
+// isValid reports whether the report follows the rules.
+func (r rules) isValid(report []int) bool {
+	_, _, broken := r.violation(report)
+	return !broken
+}
 
-	if !isDecreasingOrIncreasing(report) {
-		return false
+// Reasons for a report to be unsafe.
+const (
+	zeroStep        = "zero step"
+	stepTooSmall    = "step too small"
+	stepTooLarge    = "step too large"
+	directionChange = "direction change"
+	spanTooLarge    = "span too large"
+)
+
+// violation returns the index of the first level breaking the rules and the
+// reason, reporting false if the report follows them.
+func (r rules) violation(report []int) (int, string, bool) {
+	direction, changes := 0, 0
+	low, high := math.MaxInt, math.MinInt
+	for i, level := range report {
+		if i > 0 {
+			step := level - report[i-1]
+			switch size := intmath.Abs(step); {
+			case step == 0 && (r.strict || r.minStep > 0):
+				return i, zeroStep, true
+			case size < r.minStep:
+				return i, stepTooSmall, true
+			case size > r.maxStep:
+				return i, stepTooLarge, true
+			}
+			if step != 0 {
+				if direction != 0 && sign(step) != direction {
+					changes++
+					if changes > r.directionChanges {
+						return i, directionChange, true
+					}
+				}
+				direction = sign(step)
+			}
+		}
+
+		low, high = min(low, level), max(high, level)
+		if r.maxSpan > 0 && high-low > r.maxSpan {
+			return i, spanTooLarge, true
+		}
+	}
+	return 0, "", false
+}
+
+// stepAllowed reports whether adjacent levels may differ by step.
+func (r rules) stepAllowed(step int) bool {
+	size := intmath.Abs(step)
+	return size >= r.minStep && size <= r.maxStep && (step != 0 || !r.strict)
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+	return 1
+}
+
+// toleranceCheck returns the check of part two, allowing tolerance removals.
+func (r rules) toleranceCheck() func([]int) bool {
+	if *bruteForce {
+		return func(report []int) bool { return r.isValidWithRemovalsBruteForce(report, *tolerance) }
+	}
+	return func(report []int) bool { return r.isValidWithRemovals(report, *tolerance) }
+}
+
+// isValidWithRemovals reports whether removing at most k levels makes the
+// report valid.
+func (r rules) isValidWithRemovals(report []int, k int) bool {
+	_, ok := r.removals(report, k)
+	return ok
+}
+
+// removals returns the indices of the fewest levels to remove for the report
+// to be valid, reporting false if that takes more than k. It runs in O(n·k)
+// times the allowed direction changes. With a maximum span, the kept levels
+// must fit between one of the levels and that level plus the span, so each of
+// those windows is tried in turn, in O(n²·k).
+func (r rules) removals(report []int, k int) ([]int, bool) {
+	// an empty report has no level to start a window from
+	if r.maxSpan == 0 || len(report) == 0 {
+		return r.fewestRemovals(report, k, math.MinInt, math.MaxInt)
 	}
 
-	for i := 0; i < len(report)-1; i++ {
-		increase := report[i] - report[i+1]
-		if !slices.Contains(safeIncreases, increase) {
-			return false
+	var fewest []int
+	found := false
+	for _, low := range slices.Compact(slices.Sorted(slices.Values(report))) {
+		removed, ok := r.fewestRemovals(report, k, low, low+r.maxSpan)
+		if ok && (!found || len(removed) < len(fewest)) {
+			fewest, found = removed, true
 		}
 	}
+	return fewest, found
+}
 
-	return true
+// kept is a state of fewestRemovals: the fewest removals making the levels up
+// to one valid with that level kept, and where it comes from.
+type kept struct {
+	removals int
+	// previous is the index of the kept level before, -1 for none, reached
+	// heading in direction after changes.
+	previous, direction, changes int
 }
 
-func isDecreasingOrIncreasing(report []int) bool {
-	isAscending := slices.IsSorted(report)
-	isDescending := slices.IsSortedFunc(report, func(i, j int) int { return j - i })
-	return isAscending || isDescending
+// fewestRemovals returns the indices of the fewest levels to remove for the
+// report to be valid keeping only levels from low to high, reporting false if
+// that takes more than k. states[i][d][c] is the best way to keep level i
+// after c changes of direction, last heading in direction d: none yet, up or
+// down. Level i can only follow one of the k+1 levels before it.
+func (r rules) fewestRemovals(report []int, k, low, high int) ([]int, bool) {
+	if len(report) == 0 {
+		// valid as it is, like a single level
+		return []int{}, true
+	}
+
+	const none, up, down = 0, 1, 2
+	changes := r.directionChanges + 1
+	impossible := kept{removals: len(report) + 1}
+
+	states := make([][3][]kept, len(report))
+	best, bestIndex, bestDirection, bestChanges := len(report)+1, -1, 0, 0
+	for i, level := range report {
+		for d := range states[i] {
+			states[i][d] = slices.Repeat([]kept{impossible}, changes)
+		}
+		if level < low || level > high {
+			continue
+		}
+
+		// dropping every level before i
+		states[i][none][0] = kept{removals: i, previous: -1}
+		for j := max(0, i-k-1); j < i; j++ {
+			step := level - report[j]
+			if !r.stepAllowed(step) {
+				continue
+			}
+			for d, byChanges := range states[j] {
+				for c, state := range byChanges {
+					if state == impossible {
+						continue
+					}
+					next, nextChanges := d, c
+					if step != 0 {
+						next = up
+						if step < 0 {
+							next = down
+						}
+						if d != none && d != next {
+							nextChanges++
+						}
+					}
+					if nextChanges < changes && state.removals+i-j-1 < states[i][next][nextChanges].removals {
+						states[i][next][nextChanges] = kept{removals: state.removals + i - j - 1, previous: j, direction: d, changes: c}
+					}
+				}
+			}
+		}
+
+		// dropping every level after i
+		for d, byChanges := range states[i] {
+			for c, state := range byChanges {
+				if total := state.removals + len(report) - 1 - i; total < best {
+					best, bestIndex, bestDirection, bestChanges = total, i, d, c
+				}
+			}
+		}
+	}
+	if best > k {
+		return nil, false
+	}
+
+	keep := make([]bool, len(report))
+	for i, d, c := bestIndex, bestDirection, bestChanges; i >= 0; {
+		keep[i] = true
+		state := states[i][d][c]
+		i, d, c = state.previous, state.direction, state.changes
+	}
+	removed := make([]int, 0, best)
+	for i, isKept := range keep {
+		if !isKept {
+			removed = append(removed, i)
+		}
+	}
+	return removed, true
 }
 
 // a terrible brute force solution, I'm not proud of it.
-func isValidWithTolerance(report []int) bool {
-	if isValid(report) {
+func (r rules) isValidWithRemovalsBruteForce(report []int, k int) bool {
+	if r.isValid(report) {
 		return true
 	}
+	if k == 0 {
+		return false
+	}
 
 	for i := 0; i < len(report); i++ {
 		subslice := make([]int, 0)
@@ -108,10 +372,128 @@ func isValidWithTolerance(report []int) bool {
 			}
 			subslice = append(subslice, report[j])
 		}
-		if isValid(subslice) {
+		if r.isValidWithRemovalsBruteForce(subslice, k-1) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// explanation says why a report is unsafe and how to make it safe.
+type explanation struct {
+	// Line is the line of the report in the input, from 1.
+	Line   int   `json:"line"`
+	Levels []int `json:"levels"`
+	// Index is the index of the first level breaking the rules, from 0.
+	Index  int    `json:"index"`
+	Reason string `json:"reason"`
+	// Remove lists the indices of the fewest levels to remove for the report
+	// to be safe, nil if that takes more than the tolerance.
+	Remove []int `json:"remove"`
+}
+
+type explanations struct {
+	Tolerance int           `json:"tolerance"`
+	Unsafe    []explanation `json:"unsafe"`
+}
+
+// explain explains every unsafe report, with the levels to remove within
+// tolerance.
+func (r rules) explain(reports [][]int, tolerance int) explanations {
+	e := explanations{Tolerance: tolerance, Unsafe: make([]explanation, 0)}
+	for i, report := range reports {
+		index, reason, broken := r.violation(report)
+		if !broken {
+			continue
+		}
+		remove, _ := r.removals(report, tolerance)
+		e.Unsafe = append(e.Unsafe, explanation{Line: i + 1, Levels: report, Index: index, Reason: reason, Remove: remove})
+	}
+	return e
+}
+
+func printExplanations(e explanations, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("\n%d unsafe reports, tolerance %d:\n", len(e.Unsafe), e.Tolerance)
+	if len(e.Unsafe) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "line\tlevels\tindex\treason\tfix")
+	for _, u := range e.Unsafe {
+		levels := make([]string, len(u.Levels))
+		for i, level := range u.Levels {
+			levels[i] = strconv.Itoa(level)
+			if i == u.Index {
+				levels[i] = "[" + levels[i] + "]"
+			}
+		}
+
+		fix := "none within tolerance"
+		if u.Remove != nil {
+			removed := make([]string, len(u.Remove))
+			for i, index := range u.Remove {
+				removed[i] = fmt.Sprintf("%d at %d", u.Levels[index], index)
+			}
+			fix = "remove " + strings.Join(removed, ", ")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", u.Line, strings.Join(levels, " "), u.Index, u.Reason, fix)
+	}
+	return w.Flush()
+}
+
+// solveStreaming checks each report for both parts as it is read, keeping a
+// single report in memory.
+func solveStreaming(rules rules) {
+	file, err := os.Open("./input.txt")
+	if err != nil {
+		fmt.Printf("error reading input: error opening file: %v", err)
+		return
+	}
+	defer file.Close()
+
+	validWithTolerance := rules.toleranceCheck()
+	validCount, validWithToleranceCount := 0, 0
+	report := make([]int, 0)
+	scanner := stream.NewScanner(file)
+	for n, line := range scanner.Lines() {
+		report = report[:0]
+		for _, s := range strings.Fields(line) {
+			value, err := strconv.Atoi(s)
+			if err != nil {
+				fmt.Printf("error reading input: line %d: error converting to int: %v", n, err)
+				return
+			}
+			report = append(report, value)
+		}
+		if len(report) == 0 {
+			fmt.Printf("error reading input: line %d: empty report", n)
+			return
+		}
+
+		if rules.isValid(report) {
+			validCount++
+		}
+		if validWithTolerance(report) {
+			validWithToleranceCount++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Printf("error reading input: %v", err)
+		return
+	}
+
+	fmt.Printf("(Part one) valid count: %v\n", validCount)
+	fmt.Printf("(Part two) valid count: %v\n", validWithToleranceCount)
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testRules = []rules{
+	{minStep: 1, maxStep: 3, strict: true},
+	{minStep: 0, maxStep: 3},
+	{minStep: 1, maxStep: 3, strict: true, directionChanges: 1},
+	{minStep: 0, maxStep: 2, directionChanges: 2},
+	{minStep: 1, maxStep: 3, strict: true, maxSpan: 5},
+	{minStep: 2, maxStep: 4, strict: true, directionChanges: 1, maxSpan: 7},
+}
+
+// randomReport returns a report whose levels mostly take small steps, so that
+// a fair share of reports are valid or close to it.
+func randomReport(random *rand.Rand) []int {
+	report := make([]int, 1+random.IntN(9))
+	report[0] = random.IntN(20)
+	for i := 1; i < len(report); i++ {
+		report[i] = report[i-1] + random.IntN(9) - 4
+	}
+	return report
+}
+
+func TestRemovalsMatchBruteForce(t *testing.T) {
+	random := rand.New(rand.NewPCG(2, 24))
+	for _, r := range testRules {
+		valid := 0
+		for range 2000 {
+			report := randomReport(random)
+			for k := range 4 {
+				want := r.isValidWithRemovalsBruteForce(report, k)
+				if got := r.isValidWithRemovals(report, k); got != want {
+					t.Fatalf("%+v: report %v with %d removals: got %t, want %t", r, report, k, got, want)
+				}
+
+				removed, ok := r.removals(report, k)
+				if ok != want {
+					t.Fatalf("%+v: report %v with %d removals: removals reported %t, want %t", r, report, k, ok, want)
+				}
+				if !ok {
+					continue
+				}
+				valid++
+				if len(removed) > k {
+					t.Fatalf("%+v: report %v: removed %d levels, at most %d allowed", r, report, len(removed), k)
+				}
+				kept := make([]int, 0, len(report))
+				for i, level := range report {
+					if !slices.Contains(removed, i) {
+						kept = append(kept, level)
+					}
+				}
+				if !r.isValid(kept) {
+					t.Fatalf("%+v: report %v: removing %v leaves %v, which is not valid", r, report, removed, kept)
+				}
+			}
+		}
+		if valid == 0 {
+			t.Errorf("%+v: no report was valid, the test checks nothing", r)
+		}
+	}
+}
+
+func TestFewestRemovals(t *testing.T) {
+	r := testRules[0]
+	tests := []struct {
+		report []int
+		k      int
+		want   []int
+		ok     bool
+	}{
+		{[]int{7, 6, 4, 2, 1}, 0, []int{}, true},
+		{[]int{1, 2, 7, 8, 9}, 1, nil, false},
+		{[]int{1, 3, 2, 4, 5}, 1, []int{2}, true},
+		{[]int{8, 6, 4, 4, 1}, 1, []int{3}, true},
+		{[]int{1, 3, 6, 7, 9}, 0, []int{}, true},
+		{[]int{9, 1, 2, 3, 20}, 2, []int{0, 4}, true},
+	}
+	for _, test := range tests {
+		removed, ok := r.removals(test.report, test.k)
+		if ok != test.ok || !slices.Equal(removed, test.want) {
+			t.Errorf("%v with %d removals: got %v %t, want %v %t", test.report, test.k, removed, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestEmptyReport(t *testing.T) {
+	for _, r := range testRules {
+		for k := range 3 {
+			if !r.isValid(nil) || !r.isValidWithRemovalsBruteForce(nil, k) {
+				t.Fatalf("%+v: an empty report is not valid", r)
+			}
+			if removed, ok := r.removals([]int{}, k); !ok || len(removed) != 0 {
+				t.Errorf("%+v: empty report with %d removals: got %v %t, want [] true", r, k, removed, ok)
+			}
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	saved := []int{*minStep, *maxStep, *directionChanges, *maxSpan}
+	savedStrict := *strict
+	t.Cleanup(func() {
+		*minStep, *maxStep, *directionChanges, *maxSpan = saved[0], saved[1], saved[2], saved[3]
+		*strict = savedStrict
+	})
+
+	tests := []struct {
+		name                                        string
+		minStep, maxStep, directionChanges, maxSpan int
+		valid                                       bool
+	}{
+		{"puzzle", 1, 3, 0, 0, true},
+		{"zero steps", 0, 0, 0, 0, true},
+		{"variant", 2, 5, 3, 10, true},
+		{"negative min step", -1, 3, 0, 0, false},
+		{"max step below min step", 3, 2, 0, 0, false},
+		{"negative direction changes", 1, 3, -1, 0, false},
+		{"negative max span", 1, 3, 0, -1, false},
+	}
+	for _, test := range tests {
+		*minStep, *maxStep, *directionChanges, *maxSpan = test.minStep, test.maxStep, test.directionChanges, test.maxSpan
+		*strict = false
+		r, err := loadRules()
+		if (err == nil) != test.valid {
+			t.Errorf("%s: got error %v, want valid %t", test.name, err, test.valid)
+			continue
+		}
+		want := rules{minStep: test.minStep, maxStep: test.maxStep, directionChanges: test.directionChanges, maxSpan: test.maxSpan}
+		if test.valid && r != want {
+			t.Errorf("%s: got %+v, want %+v", test.name, r, want)
+		}
+	}
+}
+
+// TestStepRulesAgree checks that violation, which explains reports, and
+// stepAllowed, which the removals use, reject the same steps, equal levels
+// included whatever strict and the min step say.
+func TestStepRulesAgree(t *testing.T) {
+	for _, strict := range []bool{true, false} {
+		for minStep := range 3 {
+			r := rules{minStep: minStep, maxStep: 3, strict: strict}
+			for step := -5; step <= 5; step++ {
+				_, reason, broken := r.violation([]int{10, 10 + step})
+				if allowed := r.stepAllowed(step); broken == allowed {
+					t.Errorf("%+v: step %d: violation says broken %t (%s) but stepAllowed says %t", r, step, broken, reason, allowed)
+				}
+				if step == 0 && broken != (strict || minStep > 0) {
+					t.Errorf("%+v: equal levels: got broken %t", r, broken)
+				}
+			}
+		}
+	}
+}
+
+func TestViolation(t *testing.T) {
+	puzzle := testRules[0]
+	tests := []struct {
+		name   string
+		r      rules
+		report []int
+		index  int
+		reason string
+	}{
+		{"zero step", puzzle, []int{1, 2, 2, 3}, 2, zeroStep},
+		{"zero step without strict", rules{minStep: 1, maxStep: 3}, []int{5, 5}, 1, zeroStep},
+		{"too small", rules{minStep: 2, maxStep: 3, strict: true}, []int{1, 3, 4}, 2, stepTooSmall},
+		{"too large", puzzle, []int{1, 2, 6, 7}, 2, stepTooLarge},
+		{"direction change", puzzle, []int{1, 2, 3, 2, 1}, 3, directionChange},
+		{"second direction change", testRules[2], []int{1, 2, 1, 2}, 3, directionChange},
+		{"span", testRules[4], []int{1, 3, 5, 7, 9}, 3, spanTooLarge},
+		{"span going down", testRules[4], []int{9, 7, 5, 3}, 3, spanTooLarge},
+		{"safe", puzzle, []int{7, 6, 4, 2, 1}, 0, ""},
+		{"equal levels without strict", testRules[1], []int{1, 1, 2, 2}, 0, ""},
+	}
+	for _, test := range tests {
+		index, reason, broken := test.r.violation(test.report)
+		if index != test.index || reason != test.reason || broken != (test.reason != "") {
+			t.Errorf("%s: %v got %d %q %t, want %d %q", test.name, test.report, index, reason, broken, test.index, test.reason)
+		}
+	}
+}
+
+// captureStdout returns what f prints.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		output <- string(b)
+	}()
+	f()
+	w.Close()
+	return <-output
+}
+
+func TestPrintExplanations(t *testing.T) {
+	reports := [][]int{
+		{7, 6, 4, 2, 1},
+		{1, 2, 7, 8, 9},
+		{9, 7, 6, 2, 1},
+		{1, 3, 2, 4, 5},
+		{8, 6, 4, 4, 1},
+		{1, 3, 6, 7, 9},
+	}
+	e := testRules[0].explain(reports, 1)
+	want := explanations{Tolerance: 1, Unsafe: []explanation{
+		{Line: 2, Levels: reports[1], Index: 2, Reason: stepTooLarge},
+		{Line: 3, Levels: reports[2], Index: 3, Reason: stepTooLarge},
+		{Line: 4, Levels: reports[3], Index: 2, Reason: directionChange, Remove: []int{2}},
+		{Line: 5, Levels: reports[4], Index: 3, Reason: zeroStep, Remove: []int{3}},
+	}}
+	if !reflect.DeepEqual(e, want) {
+		t.Fatalf("got %+v, want %+v", e, want)
+	}
+
+	var printErr error
+	output := captureStdout(t, func() { printErr = printExplanations(e, "json") })
+	if printErr != nil {
+		t.Fatal(printErr)
+	}
+	var decoded explanations
+	decoder := json.NewDecoder(strings.NewReader(output))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&decoded); err != nil {
+		t.Fatalf("decoding %q: %v", output, err)
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("JSON round trip: got %+v, want %+v", decoded, want)
+	}
+
+	output = captureStdout(t, func() { printErr = printExplanations(e, "text") })
+	if printErr != nil {
+		t.Fatal(printErr)
+	}
+	for _, line := range []string{
+		"4 unsafe reports, tolerance 1:",
+		"2     1 2 [7] 8 9  2      step too large    none within tolerance",
+		"4     1 3 [2] 4 5  2      direction change  remove 2 at 2",
+		"5     8 6 4 [4] 1  3      zero step         remove 4 at 3",
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("text table: no line %q in\n%s", line, output)
+		}
+	}
+}
@@ -1,109 +1,644 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"aoc/params"
+	"aoc/stream"
+)
+
+var (
+	streamInput = flag.Bool("stream", false, "evaluate the memory as it is read instead of loading it, for inputs too large for memory")
+	chunkSize   = flag.Int("chunk", 64*1024, "bytes -stream reads at a time, down to 1 to check instructions split across reads")
+	annotate    = flag.Bool("annotate", false, "also print the memory in terminal colors, the instructions in green, the near misses in red and what is disabled dimmed, then why the near misses were rejected")
 )
 
+var instructionNames = params.String("instructions", "mul,do,don't", "comma separated instructions the interpreter understands, out of "+strings.Join(builtinNames(), ", "))
+
 func main() {
-	instructions, err := readInput()
+	if err := params.Parse(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	set, err := newInstructionSet(strings.Split(*instructionNames, ","))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	switch {
+	case *chunkSize < 1:
+		fmt.Printf("invalid -chunk %d, expected at least 1\n", *chunkSize)
+		return
+	case *annotate && *streamInput:
+		fmt.Println("-annotate needs the memory loaded and can't be combined with -stream")
+		return
+	}
+
+	if *streamInput {
+		solveStreaming(set)
+		return
+	}
+
+	memory, err := readInput()
 	if err != nil {
 		fmt.Printf("error reading input: %v", err)
 		return
 	}
 
-	sum := 0
-	for _, instruction := range instructions {
-		result, err := executeInstruction(instruction)
-		if err != nil {
-			fmt.Printf("error executing instruction: %v", err)
-			return
+	program, rejections := set.parse(set.lex(memory))
+
+	fmt.Printf("(Part one) Sum: %v\n", run(program, unconditional))
+	fmt.Printf("(Part two) Sum: %v\n", run(program, conditional))
+
+	if *annotate {
+		printAnnotated(memory, program, rejections)
+	}
+}
+
+func readInput() (string, error) {
+	file, err := os.ReadFile("./input.txt")
+	if err != nil {
+		return "", fmt.Errorf("error reading file: %w", err)
+	}
+	return string(file), nil
+}
+
+// registerCount is the number of registers of the interpreter.
+const registerCount = 8
+
+// definition declares an instruction of the memory language.
+type definition struct {
+	name string
+	// arity is the number of arguments, each of 1 to 3 digits.
+	arity int
+	// check rejects arguments the instruction doesn't accept, nil for any.
+	check func(arguments []int) error
+	// effect runs the instruction.
+	effect func(in *interpreter, arguments []int)
+}
+
+// builtins are the instructions that can be picked with the instructions
+// parameter. The puzzle only has mul, do and don't.
+var builtins = []definition{
+	{
+		name:  "mul",
+		arity: 2,
+		effect: func(in *interpreter, arguments []int) {
+			if in.active() {
+				in.accumulator += arguments[0] * arguments[1]
+			}
+		},
+	},
+	{
+		name:   "do",
+		effect: func(in *interpreter, _ []int) { in.enabled = true },
+	},
+	{
+		name:   "don't",
+		effect: func(in *interpreter, _ []int) { in.enabled = false },
+	},
+	{
+		name:  "add",
+		arity: 2,
+		effect: func(in *interpreter, arguments []int) {
+			if in.active() {
+				in.accumulator += arguments[0] + arguments[1]
+			}
+		},
+	},
+	{
+		name:   "toggle",
+		effect: func(in *interpreter, _ []int) { in.enabled = !in.enabled },
+	},
+	{
+		// fused multiply-add, a*b+c
+		name:  "fma",
+		arity: 3,
+		effect: func(in *interpreter, arguments []int) {
+			if in.active() {
+				in.accumulator += arguments[0]*arguments[1] + arguments[2]
+			}
+		},
+	},
+	{
+		// save copies the accumulator to a register
+		name:  "save",
+		arity: 1,
+		check: checkRegister,
+		effect: func(in *interpreter, arguments []int) {
+			if in.active() {
+				in.registers[arguments[0]] = in.accumulator
+			}
+		},
+	},
+	{
+		// restore adds a register to the accumulator
+		name:  "restore",
+		arity: 1,
+		check: checkRegister,
+		effect: func(in *interpreter, arguments []int) {
+			if in.active() {
+				in.accumulator += in.registers[arguments[0]]
+			}
+		},
+	},
+}
+
+func checkRegister(arguments []int) error {
+	if arguments[0] >= registerCount {
+		return fmt.Errorf("no register %d, there are %d", arguments[0], registerCount)
+	}
+	return nil
+}
+
+func builtinNames() []string {
+	names := make([]string, len(builtins))
+	for i, d := range builtins {
+		names[i] = d.name
+	}
+	return names
+}
+
+// instructionSet holds the instructions the interpreter understands, by name.
+type instructionSet map[string]definition
+
+// newInstructionSet returns the set of the builtins named.
+func newInstructionSet(names []string) (instructionSet, error) {
+	set := make(instructionSet)
+	for _, name := range names {
+		i := slices.IndexFunc(builtins, func(d definition) bool { return d.name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown instruction %q, expected one of %s", name, strings.Join(builtinNames(), ", "))
 		}
-		if result.instruction == mul {
-			sum += result.value
+		if err := set.register(builtins[i]); err != nil {
+			return nil, err
 		}
 	}
+	return set, nil
+}
+
+// register adds d to the set. Names are made of lowercase letters and
+// apostrophes, like the words of memory.
+func (s instructionSet) register(d definition) error {
+	if d.name == "" || strings.IndexFunc(d.name, func(r rune) bool { return r > 0x7f || !isWordByte(byte(r)) }) >= 0 {
+		return fmt.Errorf("invalid instruction name %q", d.name)
+	}
+	if _, ok := s[d.name]; ok {
+		return fmt.Errorf("instruction %q registered twice", d.name)
+	}
+	if d.arity < 0 || d.effect == nil {
+		return fmt.Errorf("invalid instruction %q, expected an arity of at least 0 and an effect", d.name)
+	}
+	s[d.name] = d
+	return nil
+}
 
-	fmt.Printf("(Part one) Sum: %v\n", sum)
+// maxDigits is the most digits an argument may have.
+const maxDigits = 3
 
-	sum = 0
-	isEnabled := true
-	for _, instruction := range instructions {
-		result, err := executeInstruction(instruction)
-		if err != nil {
-			fmt.Printf("error executing instruction: %v", err)
+type tokenKind int
+
+const (
+	// junk is a run of bytes that can't be part of an instruction.
+	junk tokenKind = iota
+	// name is the name of an instruction, which may end a longer word.
+	name
+	number
+	open
+	comma
+	closing
+)
+
+func (k tokenKind) String() string {
+	return [...]string{"junk", "name", "number", "(", ",", ")"}[k]
+}
+
+type token struct {
+	kind tokenKind
+	// pos is the offset of the token in memory.
+	pos  int
+	text string
+}
+
+// lex splits memory into tokens, scanning it once. Words are runs of lowercase
+// letters and apostrophes: one ending with the name of an instruction is split
+// into junk and that name, the longest one if several fit, so that xmul(2,4)
+// is a mul like the puzzle wants.
+func (s instructionSet) lex(memory string) []token {
+	var tokens []token
+	emit := func(kind tokenKind, start, end int) {
+		// consecutive junk is a single token
+		if kind == junk && len(tokens) > 0 && tokens[len(tokens)-1].kind == junk {
+			tokens[len(tokens)-1].text = memory[tokens[len(tokens)-1].pos:end]
 			return
 		}
-		switch result.instruction {
-		case do:
-			isEnabled = true
-		case dont:
-			isEnabled = false
-		case mul:
-			if isEnabled {
-				sum += result.value
+		tokens = append(tokens, token{kind: kind, pos: start, text: memory[start:end]})
+	}
+
+	for i := 0; i < len(memory); {
+		start := i
+		switch c := memory[i]; {
+		case c == '(':
+			i++
+			emit(open, start, i)
+		case c == ',':
+			i++
+			emit(comma, start, i)
+		case c == ')':
+			i++
+			emit(closing, start, i)
+		case isDigit(c):
+			for i < len(memory) && isDigit(memory[i]) {
+				i++
+			}
+			emit(number, start, i)
+		case isWordByte(c):
+			for i < len(memory) && isWordByte(memory[i]) {
+				i++
+			}
+			split := i
+			if d, ok := s.nameEnding(memory[start:i]); ok {
+				split -= len(d.name)
+			}
+			if split > start {
+				emit(junk, start, split)
+			}
+			if split < i {
+				emit(name, split, i)
 			}
 		default:
-			fmt.Printf("invalid instruction: %v", result.instruction)
-			return
+			for i < len(memory) && !isDigit(memory[i]) && !isWordByte(memory[i]) && !strings.ContainsRune("(,)", rune(memory[i])) {
+				i++
+			}
+			emit(junk, start, i)
 		}
 	}
+	return tokens
+}
 
-	fmt.Printf("(Part two) Sum: %v\n", sum)
+// nameEnding returns the instruction with the longest name ending word.
+func (s instructionSet) nameEnding(word string) (definition, bool) {
+	var longest definition
+	for _, d := range s {
+		if len(d.name) > len(longest.name) && strings.HasSuffix(word, d.name) {
+			longest = d
+		}
+	}
+	return longest, longest.name != ""
 }
 
-func readInput() ([]string, error) {
-	file, err := os.ReadFile("./input.txt")
-	if err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c == '\''
+}
+
+type instruction struct {
+	definition definition
+	// pos and end delimit the instruction in memory.
+	pos, end  int
+	arguments []int
+}
+
+// rejection is a near miss: the name of an instruction that isn't followed by
+// a valid call.
+type rejection struct {
+	// pos and end delimit the fragment in memory, up to what broke it.
+	pos, end int
+	reason   string
+}
+
+// parse returns the instructions found in tokens: a name, an opening
+// parenthesis, as many arguments of 1 to 3 digits separated by commas as the
+// instruction takes and a closing parenthesis, with nothing in between, the
+// arguments passing the checks of the instruction. Any other sequence is
+// corrupted memory, and the names it contains are rejected.
+func (s instructionSet) parse(tokens []token) ([]instruction, []rejection) {
+	var program []instruction
+	var rejections []rejection
+	for i := 0; i < len(tokens); {
+		if tokens[i].kind != name {
+			i++
+			continue
+		}
+		instruction, next, rejected := s.parseInstruction(tokens, i)
+		if rejected != nil {
+			rejections = append(rejections, *rejected)
+		} else {
+			program = append(program, instruction)
+		}
+		i = next
 	}
-	re := regexp.MustCompile(`(mul\(\d{1,3},\d{1,3}\))|(do\(\))|(don't\(\))`)
-	matches := re.FindAllString(string(file), -1)
+	return program, rejections
+}
+
+// parseInstruction parses the instruction named by tokens[i], returning the
+// index of the token to continue from. A rejected instruction continues from
+// the token that broke it, which may start the next one.
+func (s instructionSet) parseInstruction(tokens []token, i int) (instruction, int, *rejection) {
+	result := instruction{definition: s[tokens[i].text], pos: tokens[i].pos}
+
+	reject := func(reason string, args ...any) (instruction, int, *rejection) {
+		end := tokens[len(tokens)-1].pos + len(tokens[len(tokens)-1].text)
+		if i < len(tokens) {
+			switch tokens[i].kind {
+			case name:
+				end = tokens[i].pos
+			case junk:
+				_, size := utf8.DecodeRuneInString(tokens[i].text)
+				end = tokens[i].pos + size
+			default:
+				end = tokens[i].pos + len(tokens[i].text)
+			}
+		}
+		return instruction{}, i, &rejection{pos: result.pos, end: end, reason: fmt.Sprintf(reason, args...)}
+	}
+	expect := func(kind tokenKind) bool {
+		i++
+		return i < len(tokens) && tokens[i].kind == kind
+	}
+
+	if !expect(open) {
+		return reject("expected ( after %s, got %s", result.definition.name, describe(tokens, i))
+	}
+	for n := range result.definition.arity {
+		if n > 0 && !expect(comma) {
+			return reject("expected , before argument %d, got %s", n+1, describe(tokens, i))
+		}
+		if !expect(number) {
+			return reject("expected argument %d, got %s", n+1, describe(tokens, i))
+		}
+		if len(tokens[i].text) > maxDigits {
+			return reject("argument %d has more than %d digits", n+1, maxDigits)
+		}
+		argument, _ := strconv.Atoi(tokens[i].text)
+		result.arguments = append(result.arguments, argument)
+	}
+	if !expect(closing) {
+		return reject("expected ) after %d arguments, got %s", result.definition.arity, describe(tokens, i))
+	}
+	if check := result.definition.check; check != nil {
+		if err := check(result.arguments); err != nil {
+			return reject("%v", err)
+		}
+	}
+	result.end = tokens[i].pos + 1
+	return result, i + 1, nil
+}
 
-	return matches, nil
+// describe describes tokens[i] for a rejection.
+func describe(tokens []token, i int) string {
+	switch {
+	case i >= len(tokens):
+		return "the end of memory"
+	case tokens[i].kind == junk:
+		r, _ := utf8.DecodeRuneInString(tokens[i].text)
+		return strconv.Quote(string(r))
+	case tokens[i].kind == name:
+		return "another " + tokens[i].text
+	}
+	return strconv.Quote(tokens[i].text)
 }
 
-type instruction string
+type mode int
 
-var (
-	mul  instruction = "mul"
-	do   instruction = "do"
-	dont instruction = "don't"
+const (
+	// unconditional runs every instruction, ignoring do() and don't(), as in
+	// part one.
+	unconditional mode = iota
+	// conditional only runs the instructions enabled by the last do() or
+	// don't().
+	conditional
 )
 
-type instructionOutput struct {
-	instruction instruction
-	value       int
+// interpreter is the state the instructions act on.
+type interpreter struct {
+	mode        mode
+	enabled     bool
+	accumulator int
+	registers   [registerCount]int
+}
+
+// active reports whether instructions that are turned off by don't() run.
+func (in *interpreter) active() bool {
+	return in.enabled || in.mode == unconditional
+}
+
+// run executes the program in mode and returns the accumulator.
+func run(program []instruction, mode mode) int {
+	in := interpreter{mode: mode, enabled: true}
+	for _, instruction := range program {
+		instruction.definition.effect(&in, instruction.arguments)
+	}
+	return in.accumulator
+}
+
+// evaluator runs both parts over memory fed a byte at a time. It only keeps
+// the end of the current word and the arguments of the instruction being
+// read, so memory can be streamed in chunks of any size, instructions
+// straddling them. It accepts exactly what lex and parse do.
+type evaluator struct {
+	set instructionSet
+	// word holds the end of the current run of word bytes, as long as the
+	// longest name.
+	word    []byte
+	longest int
+	// call is the instruction whose arguments are being read, nil outside of
+	// one, and digits the number of digits of its last argument so far.
+	call      *definition
+	arguments []int
+	digits    int
+	parts     [2]interpreter
+}
+
+func newEvaluator(set instructionSet) *evaluator {
+	e := &evaluator{set: set, parts: [2]interpreter{{mode: unconditional, enabled: true}, {mode: conditional, enabled: true}}}
+	for _, d := range set {
+		e.longest = max(e.longest, len(d.name))
+	}
+	e.word = make([]byte, 0, e.longest)
+	return e
+}
+
+func (e *evaluator) feed(c byte) {
+	if e.call != nil && e.feedCall(c) {
+		return
+	}
+
+	// outside of an instruction, or c broke it and may start the next one
+	if isWordByte(c) {
+		if len(e.word) == e.longest {
+			e.word = append(e.word[:0], e.word[1:]...)
+		}
+		e.word = append(e.word, c)
+		return
+	}
+	if c == '(' {
+		if d, ok := e.set.nameEnding(string(e.word)); ok {
+			e.call, e.arguments, e.digits = &d, e.arguments[:0], 0
+		}
+	}
+	e.word = e.word[:0]
+}
+
+// feedCall reads c as part of the instruction being called, reporting false if
+// c breaks it.
+func (e *evaluator) feedCall(c byte) bool {
+	d := e.call
+	switch {
+	case isDigit(c) && e.digits < maxDigits && (e.digits > 0 || len(e.arguments) < d.arity):
+		if e.digits == 0 {
+			e.arguments = append(e.arguments, 0)
+		}
+		e.digits++
+		e.arguments[len(e.arguments)-1] = e.arguments[len(e.arguments)-1]*10 + int(c-'0')
+		return true
+	case c == ',' && e.digits > 0 && len(e.arguments) < d.arity:
+		e.digits = 0
+		return true
+	case c == ')' && len(e.arguments) == d.arity && (d.arity == 0 || e.digits > 0):
+		e.call = nil
+		if d.check == nil || d.check(e.arguments) == nil {
+			for i := range e.parts {
+				d.effect(&e.parts[i], e.arguments)
+			}
+		}
+		return true
+	}
+	e.call = nil
+	return false
+}
+
+// solveStreaming evaluates the memory as it is read, in chunks of chunkSize
+// bytes, in constant memory.
+func solveStreaming(set instructionSet) {
+	file, err := os.Open("./input.txt")
+	if err != nil {
+		fmt.Printf("error reading input: error opening file: %v", err)
+		return
+	}
+	defer file.Close()
+
+	partOne, partTwo, err := evaluateStream(file, set, *chunkSize)
+	if err != nil {
+		fmt.Printf("error reading input: %v", err)
+		return
+	}
+
+	fmt.Printf("(Part one) Sum: %v\n", partOne)
+	fmt.Printf("(Part two) Sum: %v\n", partTwo)
+}
+
+// evaluateStream evaluates the memory read from r in chunks of chunkSize bytes
+// and returns the accumulators of both parts.
+func evaluateStream(r io.Reader, set instructionSet, chunkSize int) (int, int, error) {
+	e := newEvaluator(set)
+	chunker := stream.NewChunker(r, chunkSize)
+	for _, chunk := range chunker.Chunks() {
+		for _, c := range chunk {
+			e.feed(c)
+		}
+	}
+	if err := chunker.Err(); err != nil {
+		return 0, 0, err
+	}
+	return e.parts[0].accumulator, e.parts[1].accumulator, nil
 }
 
-func executeInstruction(instruction string) (instructionOutput, error) {
-	instructionRegex := regexp.MustCompile(`[a-z']+`)
-	instructionName := instructionRegex.FindString(instruction)
+// Styles of the bytes of memory in printAnnotated, combined as bits.
+const (
+	accepted = 1 << iota
+	rejected
+	disabled
+)
+
+// printAnnotated prints memory with the program and the rejections
+// highlighted, dimming what is disabled in part two, then lists the reasons of
+// the rejections.
+func printAnnotated(memory string, program []instruction, rejections []rejection) {
+	styles := make([]int, len(memory))
+	for _, r := range rejections {
+		for i := r.pos; i < r.end; i++ {
+			styles[i] |= rejected
+		}
+	}
 
-	switch instructionName {
-	case "mul":
-		argumentRegex := regexp.MustCompile(`\d{1,3}`)
-		arguments := argumentRegex.FindAllString(instruction, -1)
-		if len(arguments) != 2 {
-			return instructionOutput{}, fmt.Errorf("invalid number of arguments: %v", arguments)
+	// an instruction is dimmed when it is disabled both before and after it
+	// runs, so do() and don't() stand out at the edges of disabled regions
+	in := interpreter{mode: conditional, enabled: true}
+	disabledFrom := -1
+	for _, instruction := range program {
+		before := in.enabled
+		instruction.definition.effect(&in, instruction.arguments)
+		if before && !in.enabled {
+			disabledFrom = instruction.end
 		}
+		if !before && in.enabled {
+			markDisabled(styles, disabledFrom, instruction.pos)
+		}
+		for i := instruction.pos; i < instruction.end; i++ {
+			styles[i] |= accepted
+			if !before && !in.enabled {
+				styles[i] |= disabled
+			}
+		}
+	}
+	if !in.enabled {
+		markDisabled(styles, disabledFrom, len(memory))
+	}
 
-		left, err := strconv.Atoi(arguments[0])
-		if err != nil {
-			return instructionOutput{}, fmt.Errorf("error converting to int: %w", err)
+	var b strings.Builder
+	for i := 0; i < len(memory); {
+		j := i
+		for j < len(memory) && styles[j] == styles[i] {
+			j++
 		}
-		right, err := strconv.Atoi(arguments[1])
-		if err != nil {
-			return instructionOutput{}, fmt.Errorf("error converting to int: %w", err)
+		b.WriteString(sgr(styles[i]))
+		b.WriteString(memory[i:j])
+		i = j
+	}
+	b.WriteString("\x1b[0m")
+	if !strings.HasSuffix(memory, "\n") {
+		b.WriteByte('\n')
+	}
+	fmt.Printf("\n%s", b.String())
+
+	fmt.Printf("\n%d instructions, %d near misses:\n", len(program), len(rejections))
+	for _, r := range rejections {
+		fmt.Printf("%8d  %-16q %s\n", r.pos, memory[r.pos:r.end], r.reason)
+	}
+}
+
+// markDisabled dims memory[from:to], except for the instructions it contains
+// that were disabled by themselves.
+func markDisabled(styles []int, from, to int) {
+	for i := max(from, 0); i < to; i++ {
+		if styles[i]&accepted == 0 {
+			styles[i] |= disabled
 		}
-		return instructionOutput{instruction: mul, value: left * right}, nil
-	case "do":
-		return instructionOutput{instruction: do}, nil
-	case "don't":
-		return instructionOutput{instruction: dont}, nil
-	default:
-		return instructionOutput{}, fmt.Errorf("invalid instruction: %v", instructionName)
 	}
 }
+
+// sgr returns the terminal escape sequence of style.
+func sgr(style int) string {
+	codes := []string{"0"}
+	if style&disabled != 0 {
+		codes = append(codes, "2")
+	}
+	switch {
+	case style&accepted != 0:
+		codes = append(codes, "32")
+	case style&rejected != 0:
+		codes = append(codes, "31", "4")
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func puzzleSet(t *testing.T) instructionSet {
+	t.Helper()
+	set, err := newInstructionSet([]string{"mul", "do", "don't"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+// evaluateMemory evaluates memory with the lexer and parser.
+func evaluateMemory(set instructionSet, memory string) (int, int) {
+	program, _ := set.parse(set.lex(memory))
+	return run(program, unconditional), run(program, conditional)
+}
+
+func TestStreamingMatchesParser(t *testing.T) {
+	tests := []struct {
+		name             string
+		memory           string
+		partOne, partTwo int
+	}{
+		{"empty", "", 0, 0},
+		{"part one example", "xmul(2,4)%&mul[3,7]!@^do_not_mul(5,5)+mul(32,64]then(mul(11,8)mul(8,5))", 161, 161},
+		{"part two example", "xmul(2,4)&mul[3,7]!^don't()_mul(5,5)+mul(32,64](mul(11,8)undo()?mul(8,5))", 161, 48},
+		{"mul split at every byte", "mul(123,456)", 56088, 56088},
+		{"don't and do split", "don't()mul(2,3)do()mul(4,5)", 26, 20},
+		{"name inside a word", "'mulmul(2,2)xdon't()amul(3,3)", 13, 4},
+		{"too many digits", "mul(1234,5)mul(12,3456)mul(999,999)", 998001, 998001},
+		{"broken call starts the next one", "mul(1,mul(2,3)mul(4*mul(5,6)", 36, 36},
+		{"extra argument", "mul(1,2,3)mul(2,2)", 4, 4},
+		{"do with arguments", "don't()do(1)mul(2,2)", 4, 0},
+		{"unfinished", "mul(2,3)don't()do(", 6, 6},
+		{"unfinished mul", "mul(2,3)mul(4,5", 6, 6},
+		{"across lines", "mul(2,\n3)mul(2,3)\ndon't()\nmul(1,1)", 7, 6},
+	}
+
+	set := puzzleSet(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			partOne, partTwo := evaluateMemory(set, test.memory)
+			if partOne != test.partOne || partTwo != test.partTwo {
+				t.Fatalf("parser: got %d and %d, want %d and %d", partOne, partTwo, test.partOne, test.partTwo)
+			}
+
+			for chunkSize := 1; chunkSize <= max(len(test.memory), 1); chunkSize++ {
+				streamOne, streamTwo, err := evaluateStream(strings.NewReader(test.memory), set, chunkSize)
+				if err != nil {
+					t.Fatalf("chunks of %d: %v", chunkSize, err)
+				}
+				if streamOne != partOne || streamTwo != partTwo {
+					t.Errorf("chunks of %d: got %d and %d, want %d and %d", chunkSize, streamOne, streamTwo, partOne, partTwo)
+				}
+			}
+		})
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	tests := []struct {
+		name             string
+		instructions     []string
+		memory           string
+		partOne, partTwo int
+	}{
+		{"mul", []string{"mul"}, "mul(6,7)mul(1,2", 42, 42},
+		{"do and don't", []string{"mul", "do", "don't"}, "don't()mul(2,2)do()mul(3,3)", 13, 9},
+		{"add", []string{"add", "don't"}, "add(1,2)add(3)don't()add(10,20)", 33, 3},
+		{"toggle", []string{"mul", "toggle"}, "toggle()mul(2,2)toggle()mul(3,3)toggle(1)mul(4,4)", 29, 25},
+		{"fma", []string{"fma", "don't"}, "fma(2,3,4)fma(2,3)don't()fma(1,1,1)", 12, 10},
+		{"save and restore", []string{"mul", "save", "restore"}, "mul(2,3)save(1)restore(1)restore(7)", 12, 12},
+		{"restore of an unsaved register", []string{"mul", "restore"}, "mul(1,5)restore(3)", 5, 5},
+		{"save disabled", []string{"mul", "save", "restore", "don't"}, "mul(2,2)don't()save(0)restore(0)", 8, 4},
+		{"register out of range", []string{"mul", "save", "restore"}, "mul(1,1)save(8)restore(8)save(12)restore(0)", 1, 1},
+		{"unregistered instruction", []string{"mul"}, "don't()mul(2,2)add(1,1)", 4, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			set, err := newInstructionSet(test.instructions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			partOne, partTwo := evaluateMemory(set, test.memory)
+			if partOne != test.partOne || partTwo != test.partTwo {
+				t.Errorf("parser: got %d and %d, want %d and %d", partOne, partTwo, test.partOne, test.partTwo)
+			}
+			streamOne, streamTwo, err := evaluateStream(strings.NewReader(test.memory), set, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if streamOne != test.partOne || streamTwo != test.partTwo {
+				t.Errorf("stream: got %d and %d, want %d and %d", streamOne, streamTwo, test.partOne, test.partTwo)
+			}
+		})
+	}
+}
+
+func TestRejections(t *testing.T) {
+	set, err := newInstructionSet([]string{"mul", "save"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory := "mul(1,2save(9)mul(1234,1)mul[save("
+	_, rejections := set.parse(set.lex(memory))
+
+	want := []struct{ fragment, reason string }{
+		{"mul(1,2", `expected ) after 2 arguments, got another save`},
+		{"save(9)", "no register 9, there are 8"},
+		{"mul(1234", "argument 1 has more than 3 digits"},
+		{"mul[", `expected ( after mul, got "["`},
+		{"save(", "expected argument 1, got the end of memory"},
+	}
+	if len(rejections) != len(want) {
+		t.Fatalf("got %d rejections, want %d: %v", len(rejections), len(want), rejections)
+	}
+	for i, r := range rejections {
+		if fragment := memory[r.pos:r.end]; fragment != want[i].fragment || r.reason != want[i].reason {
+			t.Errorf("rejection %d: got %q %q, want %q %q", i, fragment, r.reason, want[i].fragment, want[i].reason)
+		}
+	}
+}
+
+func TestNewInstructionSet(t *testing.T) {
+	tests := []struct {
+		names []string
+		err   string
+	}{
+		{[]string{"mul", "do", "don't", "add", "toggle", "fma", "save", "restore"}, ""},
+		{[]string{"mul", "sub"}, `unknown instruction "sub"`},
+		{[]string{""}, `unknown instruction ""`},
+		{[]string{"mul", "mul"}, `instruction "mul" registered twice`},
+	}
+	for _, test := range tests {
+		_, err := newInstructionSet(test.names)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%v: %v", test.names, err)
+		case test.err != "" && (err == nil || !strings.HasPrefix(err.Error(), test.err)):
+			t.Errorf("%v: got error %v, want %s", test.names, err, test.err)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	effect := func(*interpreter, []int) {}
+	tests := []struct {
+		definition definition
+		err        string
+	}{
+		{definition{name: "nop", effect: effect}, ""},
+		{definition{name: "", effect: effect}, `invalid instruction name ""`},
+		{definition{name: "Mul", effect: effect}, `invalid instruction name "Mul"`},
+		{definition{name: "mul2", effect: effect}, `invalid instruction name "mul2"`},
+		{definition{name: "négation", effect: effect}, `invalid instruction name "négation"`},
+		{definition{name: "neg", arity: -1, effect: effect}, `invalid instruction "neg"`},
+		{definition{name: "neg", arity: 1}, `invalid instruction "neg"`},
+	}
+	for _, test := range tests {
+		err := make(instructionSet).register(test.definition)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%q: %v", test.definition.name, err)
+		case test.err != "" && (err == nil || !strings.HasPrefix(err.Error(), test.err)):
+			t.Errorf("%q: got error %v, want %s", test.definition.name, err, test.err)
+		}
+	}
+}
+
+// TestCustomInstruction registers an instruction that is not a builtin, with
+// its own check, the way a new one would be tried out.
+func TestCustomInstruction(t *testing.T) {
+	set := puzzleSet(t)
+	err := set.register(definition{
+		name:  "sub",
+		arity: 2,
+		check: func(arguments []int) error {
+			if arguments[0] < arguments[1] {
+				return fmt.Errorf("%d is less than %d", arguments[0], arguments[1])
+			}
+			return nil
+		},
+		effect: func(in *interpreter, arguments []int) {
+			if in.active() {
+				in.accumulator += arguments[0] - arguments[1]
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partOne, partTwo := evaluateMemory(set, "sub(10,3)sub(3,10)don't()sub(5,1)mul(2,2)")
+	if partOne != 15 || partTwo != 7 {
+		t.Errorf("got %d and %d, want 15 and 7", partOne, partTwo)
+	}
+}
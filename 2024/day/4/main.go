@@ -4,16 +4,28 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+
+	"aoc/params"
+)
+
+var (
+	word      = params.String("word", "XMAS", "word searched for in part one")
+	crossWord = params.String("cross-word", "MAS", "word forming the cross in part two")
 )
 
 func main() {
+	if err := params.Parse(); err != nil {
+		fmt.Printf("error parsing parameters: %v", err)
+		return
+	}
+
 	input, err := readInput()
 	if err != nil {
 		fmt.Printf("error reading input: %v", err)
 		return
 	}
 
-	substring := "XMAS"
+	substring := *word
 	count := findSubstringInAllDirections(input, substring, settings{
 		shouldSearchHorizontally: true,
 		shouldSearchVertically:   true,
@@ -21,7 +33,7 @@ func main() {
 	})
 	fmt.Printf("(Part one) Substring %v appears %v times\n", substring, count)
 
-	substring = "MAS"
+	substring = *crossWord
 	submatrices := createAllSquareSubmatrices(input, len(substring))
 	count = 0
 	for _, submatrix := range submatrices {
@@ -8,6 +8,8 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+
+	"aoc/graph"
 )
 
 func main() {
@@ -20,7 +22,7 @@ func main() {
 	validSum := 0
 	invalidSum := 0
 	for _, update := range updates {
-		if isValid, _ := isUpdateValid(rules, update); isValid {
+		if isUpdateValid(rules, update) {
 			middleElement := update[len(update)/2]
 			validSum += middleElement
 		} else {
@@ -38,7 +40,9 @@ func main() {
 	fmt.Printf("(Part two) Sum of middle elements of invalid updates: %d\n", invalidSum)
 }
 
-func readInput() (rules map[int][]int, updates [][]int, err error) {
+// readInput returns the ordering rules as a graph with an edge X -> Y for every
+// rule "X|Y", i.e. X has to be printed before Y.
+func readInput() (rules *graph.Graph[int], updates [][]int, err error) {
 	file, err := os.Open("./input.txt")
 	if err != nil {
 		return nil, nil, fmt.Errorf("error opening file: %w", err)
@@ -48,7 +52,7 @@ func readInput() (rules map[int][]int, updates [][]int, err error) {
 	scanner := bufio.NewScanner(file)
 	rulesRe := regexp.MustCompile(`^\d+\|\d+$`)
 	updatesRe := regexp.MustCompile(`^\d+(,\d+)*$`)
-	rules = make(map[int][]int)
+	rules = graph.New[int]()
 	updates = make([][]int, 0)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -56,17 +60,17 @@ func readInput() (rules map[int][]int, updates [][]int, err error) {
 		if rulesRe.MatchString(line) {
 			values := strings.Split(line, "|")
 
-			value, err := strconv.Atoi(values[0])
+			before, err := strconv.Atoi(values[0])
 			if err != nil {
 				return nil, nil, fmt.Errorf("error converting key to int: %w", err)
 			}
 
-			key, err := strconv.Atoi(values[1])
+			after, err := strconv.Atoi(values[1])
 			if err != nil {
 				return nil, nil, fmt.Errorf("error converting value to int: %w", err)
 			}
 
-			rules[key] = append(rules[key], value)
+			rules.AddEdge(before, after)
 		}
 
 		if updatesRe.MatchString(line) {
@@ -87,55 +91,24 @@ func readInput() (rules map[int][]int, updates [][]int, err error) {
 	return rules, updates, nil
 }
 
-func isUpdateValid(rules map[int][]int, update []int) (isValid bool, faultyIndex int) {
-	reversedUpdate := make([]int, len(update))
-	copy(reversedUpdate, update)
-	slices.Reverse(reversedUpdate)
-
-	for i, value := range reversedUpdate {
-		numbersToCheck, ok := rules[value]
-		if !ok {
-			continue
-		}
-
-		remainingValues := reversedUpdate[:i]
-		violatesRule := slices.ContainsFunc(remainingValues, func(i int) bool {
-			return slices.Contains(numbersToCheck, i)
+func isUpdateValid(rules *graph.Graph[int], update []int) bool {
+	for i, value := range update {
+		violatesRule := slices.ContainsFunc(update[i+1:], func(later int) bool {
+			return rules.HasEdge(later, value)
 		})
 		if violatesRule {
-			return false, len(reversedUpdate) - i
+			return false
 		}
 	}
 
-	return true, -1
+	return true
 }
 
-func fixUpdate(rules map[int][]int, update []int) ([]int, error) {
-	updateCopy := make([]int, len(update))
-	copy(updateCopy, update)
-
-	if isValid, _ := isUpdateValid(rules, updateCopy); isValid {
-		return updateCopy, nil
+func fixUpdate(rules *graph.Graph[int], update []int) ([]int, error) {
+	fixedUpdate, err := rules.Subgraph(update).TopologicalSort()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fix update: %w", err)
 	}
 
-	for {
-		isValid, faultyIndex := isUpdateValid(rules, updateCopy)
-		if isValid {
-			return updateCopy, nil
-		}
-		tempCopy := make([]int, len(updateCopy))
-		copy(tempCopy, updateCopy)
-
-		if faultyIndex != 0 {
-			faultyElement := updateCopy[faultyIndex]
-			newUpdate := make([]int, 0)
-			newUpdate = append(newUpdate, faultyElement)
-			newUpdate = append(newUpdate, updateCopy[:faultyIndex]...)
-			newUpdate = append(newUpdate, updateCopy[faultyIndex+1:]...)
-			copy(updateCopy, newUpdate)
-		}
-		if slices.Equal(updateCopy, tempCopy) {
-			return nil, fmt.Errorf("unable to fix update")
-		}
-	}
+	return fixedUpdate, nil
 }
@@ -2,18 +2,41 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
+
+	"aoc/grid"
+	"aoc/trace"
+	"aoc/visual"
+)
+
+var (
+	visualize  = flag.String("visualize", "", "write the guard's path and the looping obstructions as JSON to `file`")
+	traceSteps = flag.String("trace-steps", "", "write the guard's turns and the verdict on each obstruction as JSON Lines to `file`")
 )
 
 func main() {
+	flag.Parse()
+
 	board, err := readInput()
 	if err != nil {
 		fmt.Printf("error reading input: %v", err)
 		return
 	}
 
-	path, isLooping, err := findPath(board)
+	tracer, err := trace.Open(*traceSteps)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer func() {
+		if err := tracer.Close(); err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	path, isLooping, err := findPath(board, tracer)
 	if err != nil {
 		fmt.Printf("error finding path: %v", err)
 		return
@@ -23,22 +46,28 @@ func main() {
 		return
 	}
 
-	uniquePositions := make(map[coordinate]bool)
+	uniquePositions := make(map[grid.Point]bool)
 	for _, step := range path {
-		uniquePositions[step.coordinate] = true
+		uniquePositions[step.Point] = true
 	}
 	uniquePositionsCount := len(uniquePositions)
 	fmt.Printf("(Part one) Unique positions visited: %v\n", uniquePositionsCount)
 
 	obstructions := getPossibleObstructions(path)
 
-	validObstructionCount := 0
+	validObstructions := make([]grid.Point, 0)
 	for _, obstruction := range obstructions {
-		if evaluateObstruction(board, obstruction) {
-			validObstructionCount++
+		if evaluateObstruction(board, obstruction, tracer) {
+			validObstructions = append(validObstructions, obstruction)
+		}
+	}
+	fmt.Printf("(Part two) Valid obstructions: %v\n", len(validObstructions))
+
+	if *visualize != "" {
+		if err := visualizePath(board, path, validObstructions).WriteFile(*visualize); err != nil {
+			fmt.Println(err)
 		}
 	}
-	fmt.Printf("(Part two) Valid obstructions: %v\n", validObstructionCount)
 }
 
 func readInput() ([][]rune, error) {
@@ -79,75 +108,33 @@ func readInput() ([][]rune, error) {
 	return text, nil
 }
 
-type direction rune
-
-const (
-	up    direction = '^'
-	down  direction = 'v'
-	left  direction = '<'
-	right direction = '>'
-)
-
-type coordinate struct {
-	x int
-	y int
-}
-
-func (c coordinate) move(d direction) coordinate {
-	switch d {
-	case up:
-		return coordinate{x: c.x, y: c.y - 1}
-	case down:
-		return coordinate{x: c.x, y: c.y + 1}
-	case left:
-		return coordinate{x: c.x - 1, y: c.y}
-	case right:
-		return coordinate{x: c.x + 1, y: c.y}
-	}
-	return c
-}
-
-func rotateRight(dir direction) direction {
-	switch dir {
-	case up:
-		return right
-	case right:
-		return down
-	case down:
-		return left
-	case left:
-		return up
-	}
-	return up
-}
-
-func (c coordinate) isOutOfBounds(board [][]rune) bool {
-	return c.y < 0 || c.y >= len(board) || c.x < 0 || c.x >= len(board[0])
-}
-
-func findGuard(board [][]rune) (coordinate, error) {
+func findGuard(board [][]rune) (grid.Point, grid.Direction, error) {
 	for y, row := range board {
 		for x, cell := range row {
-			if cell == rune(up) || cell == rune(down) || cell == rune(left) || cell == rune(right) {
-				return coordinate{x: x, y: y}, nil
+			for _, d := range grid.Directions {
+				if cell == d.Rune() {
+					return grid.Point{X: x, Y: y}, d, nil
+				}
 			}
 		}
 	}
-	return coordinate{}, fmt.Errorf("guard not found")
+	return grid.Point{}, grid.Up, fmt.Errorf("guard not found")
 }
 
 type pathStep struct {
-	coordinate
-	direction
+	grid.Point
+	grid.Direction
 }
 
-func findPath(board [][]rune) (path []pathStep, isLooping bool, err error) {
+// findPath walks the guard until it leaves the board or loops, reporting each
+// turn to tracer.
+func findPath(board [][]rune, tracer trace.Tracer) (path []pathStep, isLooping bool, err error) {
 	boardCopy := make([][]rune, len(board))
 	for i, row := range board {
 		boardCopy[i] = make([]rune, len(row))
 		copy(boardCopy[i], row)
 	}
-	guard, err := findGuard(boardCopy)
+	guard, direction, err := findGuard(boardCopy)
 	if err != nil {
 		return []pathStep{}, false, fmt.Errorf("error finding guard: %w", err)
 	}
@@ -155,50 +142,61 @@ func findPath(board [][]rune) (path []pathStep, isLooping bool, err error) {
 	visitedTurns := map[pathStep]bool{}
 
 	path = []pathStep{}
-	direction := direction(boardCopy[guard.y][guard.x])
+	if tracer.Enabled() {
+		tracer.Snapshot("part one", fmt.Sprintf("guard starts at %d,%d facing %c", guard.X, guard.Y, direction.Rune()), traceBoard(boardCopy, path, guard, direction))
+	}
 	for {
-		if guard.isOutOfBounds(boardCopy) {
+		if !grid.Within(guard, boardCopy) {
 			break
 		}
 
-		target := guard.move(direction)
-		isTargetOutOfBounds := target.isOutOfBounds(boardCopy)
+		target := guard.Move(direction)
+		isTargetOutOfBounds := !grid.Within(target, boardCopy)
 
 		if !isTargetOutOfBounds {
-			targetCell := boardCopy[target.y][target.x]
+			targetCell := boardCopy[target.Y][target.X]
 			for targetCell == '#' {
-				direction = rotateRight(direction)
-				target = guard.move(direction)
-				targetCell = boardCopy[target.y][target.x]
-
-				if visitedTurns[pathStep{coordinate: target, direction: direction}] {
+				blocked := target
+				direction = direction.TurnRight()
+				target = guard.Move(direction)
+				targetCell = boardCopy[target.Y][target.X]
+				tracer.Decision("part one", "blocked at %d,%d, turning to face %c", blocked.X, blocked.Y, direction.Rune())
+
+				if visitedTurns[pathStep{Point: target, Direction: direction}] {
+					tracer.Decision("part one", "already turned at %d,%d to face %c, the path loops", guard.X, guard.Y, direction.Rune())
 					return path, true, nil
 				}
-				visitedTurns[pathStep{coordinate: target, direction: direction}] = true
+				visitedTurns[pathStep{Point: target, Direction: direction}] = true
+				if tracer.Enabled() {
+					tracer.Snapshot("part one", fmt.Sprintf("step %d at %d,%d facing %c", len(path), guard.X, guard.Y, direction.Rune()), traceBoard(boardCopy, path, guard, direction))
+				}
 			}
 		}
 
-		path = append(path, pathStep{coordinate: guard, direction: direction})
-		boardCopy[guard.y][guard.x] = '.'
+		path = append(path, pathStep{Point: guard, Direction: direction})
+		boardCopy[guard.Y][guard.X] = '.'
 
 		if isTargetOutOfBounds {
+			if tracer.Enabled() {
+				tracer.Snapshot("part one", fmt.Sprintf("guard leaves the board after %d steps", len(path)), traceBoard(boardCopy, path, guard, direction))
+			}
 			break
 		}
 
-		boardCopy[target.y][target.x] = rune(direction)
+		boardCopy[target.Y][target.X] = direction.Rune()
 		guard = target
 	}
 
 	return path, false, nil
 }
 
-func getPossibleObstructions(path []pathStep) []coordinate {
-	uniquePositions := make(map[coordinate]bool)
+func getPossibleObstructions(path []pathStep) []grid.Point {
+	uniquePositions := make(map[grid.Point]bool)
 	for _, step := range path {
-		uniquePositions[step.coordinate] = true
+		uniquePositions[step.Point] = true
 	}
 
-	obstructions := []coordinate{}
+	obstructions := []grid.Point{}
 	for step := range uniquePositions {
 		obstructions = append(obstructions, step)
 	}
@@ -206,24 +204,68 @@ func getPossibleObstructions(path []pathStep) []coordinate {
 	return obstructions
 }
 
-func evaluateObstruction(board [][]rune, obstruction coordinate) bool {
+func evaluateObstruction(board [][]rune, obstruction grid.Point, tracer trace.Tracer) bool {
 	boardCopy := make([][]rune, len(board))
 	for i, row := range board {
 		boardCopy[i] = make([]rune, len(row))
 		copy(boardCopy[i], row)
 	}
 
-	if boardCopy[obstruction.y][obstruction.x] != '.' {
+	if boardCopy[obstruction.Y][obstruction.X] != '.' {
+		tracer.Decision("part two", "skipping obstruction at %d,%d: cell is %c", obstruction.X, obstruction.Y, boardCopy[obstruction.Y][obstruction.X])
 		return false
 	}
 
-	boardCopy[obstruction.y][obstruction.x] = '#'
+	boardCopy[obstruction.Y][obstruction.X] = '#'
 
-	_, isLooping, err := findPath(boardCopy)
+	// The paths of the candidates would make the trace huge, only the
+	// verdicts are traced.
+	path, isLooping, err := findPath(boardCopy, trace.Nop{})
 	if err != nil {
 		fmt.Printf("error finding path: %v", err)
 		return false
 	}
 
+	if isLooping {
+		tracer.Decision("part two", "obstruction at %d,%d makes the guard loop after %d steps", obstruction.X, obstruction.Y, len(path))
+	} else {
+		tracer.Decision("part two", "obstruction at %d,%d: guard leaves after %d steps", obstruction.X, obstruction.Y, len(path))
+	}
+
 	return isLooping
 }
+
+// traceBoard snapshots board with the path walked so far marked with X.
+func traceBoard(board [][]rune, path []pathStep, guard grid.Point, dir grid.Direction) trace.GridState {
+	rows := make([][]rune, len(board))
+	for i, row := range board {
+		rows[i] = make([]rune, len(row))
+		copy(rows[i], row)
+	}
+	for _, step := range path {
+		rows[step.Y][step.X] = 'X'
+	}
+	rows[guard.Y][guard.X] = dir.Rune()
+
+	return trace.Grid(rows, map[string]any{"steps": len(path), "guard": []int{guard.X, guard.Y}})
+}
+
+func visualizePath(board [][]rune, path []pathStep, obstructions []grid.Point) *visual.Visualization {
+	v := visual.FromRunes("Guard path", board)
+	v.Palette["#"] = "#444"
+	v.Cumulative = true
+
+	for i, step := range path {
+		v.AddFrame(fmt.Sprintf("step %d, facing %c", i, step.Direction.Rune()), []visual.Cell{
+			{X: step.X, Y: step.Y, Color: visual.Gradient(float64(i) / float64(len(path)))},
+		})
+	}
+
+	cells := make([]visual.Cell, len(obstructions))
+	for i, obstruction := range obstructions {
+		cells[i] = visual.Cell{X: obstruction.X, Y: obstruction.Y, Color: "red"}
+	}
+	v.AddFrame(fmt.Sprintf("%d obstructions making the guard loop", len(obstructions)), cells)
+
+	return v
+}
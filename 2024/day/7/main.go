@@ -2,13 +2,26 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+
+	"aoc/intmath"
+	"aoc/stream"
 )
 
+var streamInput = flag.Bool("stream", false, "check the equations line by line instead of loading them, for inputs too large for memory")
+
 func main() {
+	flag.Parse()
+
+	if *streamInput {
+		solveStreaming()
+		return
+	}
+
 	equations, err := readInput()
 	if err != nil {
 		fmt.Printf("error reading input: %v", err)
@@ -72,18 +85,15 @@ const (
 	concat operator = "||"
 )
 
-func apply(a int, op operator, b int) int {
+// apply returns a op b and false if the result does not fit in an int.
+func apply(a int, op operator, b int) (int, bool) {
 	switch op {
 	case add:
-		return a + b
+		return intmath.CheckedAdd(a, b)
 	case mul:
-		return a * b
+		return intmath.CheckedMul(a, b)
 	case concat:
-		multiplier := 1
-		for temp := b; temp > 0; temp /= 10 {
-			multiplier *= 10
-		}
-		return a*multiplier + b
+		return intmath.CheckedConcat(a, b)
 	}
 	panic("unknown operator")
 }
@@ -94,7 +104,12 @@ func isEquationPossible(eq equation, operators []operator) bool {
 	}
 
 	for _, operator := range operators {
-		operationResult := apply(eq.numbers[0], operator, eq.numbers[1])
+		operationResult, ok := apply(eq.numbers[0], operator, eq.numbers[1])
+		if !ok {
+			// the numbers are never negative, so the running value can't
+			// come back down to a result that fits
+			continue
+		}
 		newNumbers := make([]int, len(eq.numbers)-1)
 		newNumbers[0] = operationResult
 		copy(newNumbers[1:], eq.numbers[2:])
@@ -119,3 +134,65 @@ func getCalibrationResult(equations []equation, operators []operator) int {
 
 	return result
 }
+
+// solveStreaming checks each equation for both parts as it is read. The totals
+// of huge inputs can exceed an int, so they are summed exactly.
+func solveStreaming() {
+	file, err := os.Open("./input.txt")
+	if err != nil {
+		fmt.Printf("error reading input: error opening file: %v", err)
+		return
+	}
+	defer file.Close()
+
+	var partOne, partTwo intmath.Sum
+	scanner := stream.NewScanner(file)
+	for n, line := range scanner.Lines() {
+		eq, err := parseEquation(line)
+		if err != nil {
+			fmt.Printf("error reading input: line %d: %v", n, err)
+			return
+		}
+
+		if isEquationPossible(eq, []operator{add, mul}) {
+			partOne.Add(eq.result)
+		}
+		if isEquationPossible(eq, []operator{add, mul, concat}) {
+			partTwo.Add(eq.result)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Printf("error reading input: %v", err)
+		return
+	}
+
+	fmt.Printf("(Part one) Total calibration result: %s\n", partOne.String())
+	fmt.Printf("(Part two) Total calibration result: %s\n", partTwo.String())
+}
+
+func parseEquation(line string) (equation, error) {
+	resultText, numbersText, ok := strings.Cut(line, ": ")
+	if !ok {
+		return equation{}, fmt.Errorf("missing \": \" in %q", line)
+	}
+
+	result, err := strconv.Atoi(resultText)
+	if err != nil {
+		return equation{}, fmt.Errorf("error converting result value to int: %w", err)
+	}
+
+	fields := strings.Fields(numbersText)
+	eq := equation{result: result, numbers: make([]int, len(fields))}
+	for i, field := range fields {
+		eq.numbers[i], err = strconv.Atoi(field)
+		if err != nil {
+			return equation{}, fmt.Errorf("error converting number to int: %w", err)
+		}
+	}
+	if len(eq.numbers) == 0 {
+		return equation{}, fmt.Errorf("no numbers in %q", line)
+	}
+
+	return eq, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIsEquationPossible(t *testing.T) {
+	// what the concatenation of 922337203685477580 and 8 wraps around to
+	wrapped := 922337203685477580
+	wrapped = wrapped*10 + 8
+
+	tests := []struct {
+		eq      equation
+		partOne bool
+		partTwo bool
+	}{
+		{equation{190, []int{10, 19}}, true, true},
+		{equation{3267, []int{81, 40, 27}}, true, true},
+		{equation{156, []int{15, 6}}, false, true},
+		{equation{7290, []int{6, 8, 6, 15}}, false, true},
+		{equation{21037, []int{9, 7, 18, 13}}, false, false},
+		{equation{math.MaxInt, []int{922337203685477580, 7}}, false, true},
+		{equation{wrapped, []int{922337203685477580, 8}}, false, false},
+		// the product wraps to 0
+		{equation{0, []int{1 << 32, 1 << 32}}, false, false},
+	}
+	for _, test := range tests {
+		if got := isEquationPossible(test.eq, []operator{add, mul}); got != test.partOne {
+			t.Errorf("%v with + and *: got %t, want %t", test.eq, got, test.partOne)
+		}
+		if got := isEquationPossible(test.eq, []operator{add, mul, concat}); got != test.partTwo {
+			t.Errorf("%v with +, * and ||: got %t, want %t", test.eq, got, test.partTwo)
+		}
+	}
+}
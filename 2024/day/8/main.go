@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+
+	"aoc/grid"
 )
 
 func main() {
@@ -62,60 +64,44 @@ func readInput() ([][]rune, error) {
 	return text, nil
 }
 
-type coordinate struct {
-	x int
-	y int
-}
-
-func (c coordinate) findOtherEnd(center coordinate) coordinate {
-	x := center.x + (center.x - c.x)
-	y := center.y + (center.y - c.y)
-	return coordinate{x: x, y: y}
-}
-
-func (c coordinate) isWithinBounds(layout [][]rune) bool {
-	if c.x < 0 || c.y < 0 {
-		return false
-	}
-	if c.y >= len(layout) || c.x >= len(layout[0]) {
-		return false
-	}
-	return true
+// findOtherEnd mirrors c through center.
+func findOtherEnd(c, center grid.Point) grid.Point {
+	return center.Scale(2).Sub(c)
 }
 
-func getAntennaInfo(layout [][]rune) map[rune][]coordinate {
-	antennas := make(map[rune][]coordinate)
+func getAntennaInfo(layout [][]rune) map[rune][]grid.Point {
+	antennas := make(map[rune][]grid.Point)
 	for y, line := range layout {
 		for x, char := range line {
 			if char == '.' {
 				continue
 			}
-			antennas[char] = append(antennas[char], coordinate{x: x, y: y})
+			antennas[char] = append(antennas[char], grid.Point{X: x, Y: y})
 		}
 	}
 	return antennas
 }
 
-func getAllPairs(coordinates []coordinate) [][]coordinate {
-	pairs := make([][]coordinate, 0)
+func getAllPairs(coordinates []grid.Point) [][]grid.Point {
+	pairs := make([][]grid.Point, 0)
 	for i, c1 := range coordinates {
 		for j, c2 := range coordinates {
 			if i == j {
 				continue
 			}
-			pairs = append(pairs, []coordinate{c1, c2})
+			pairs = append(pairs, []grid.Point{c1, c2})
 		}
 	}
 	return pairs
 }
 
-func getResonantAntinodes(antennaInfo map[rune][]coordinate, layout [][]rune) []coordinate {
-	antinodes := []coordinate{}
+func getResonantAntinodes(antennaInfo map[rune][]grid.Point, layout [][]rune) []grid.Point {
+	antinodes := []grid.Point{}
 	for _, coordinates := range antennaInfo {
 		pairs := getAllPairs(coordinates)
 		for _, pair := range pairs {
-			antinode := pair[0].findOtherEnd(pair[1])
-			if antinode.isWithinBounds(layout) {
+			antinode := findOtherEnd(pair[0], pair[1])
+			if grid.Within(antinode, layout) {
 				antinodes = append(antinodes, antinode)
 			}
 		}
@@ -123,23 +109,23 @@ func getResonantAntinodes(antennaInfo map[rune][]coordinate, layout [][]rune) []
 	return antinodes
 }
 
-func getLinearAntinodes(antennaInfo map[rune][]coordinate, layout [][]rune) []coordinate {
-	linearAntiNodes := []coordinate{}
+func getLinearAntinodes(antennaInfo map[rune][]grid.Point, layout [][]rune) []grid.Point {
+	linearAntiNodes := []grid.Point{}
 	for _, coordinates := range antennaInfo {
 		pairs := getAllPairs(coordinates)
 		for _, pair := range pairs {
 			linearAntiNodes = append(linearAntiNodes, pair[0])
 
-			antinode := pair[0].findOtherEnd(pair[1])
-			if antinode.isWithinBounds(layout) {
-				layout[antinode.y][antinode.x] = '#'
+			antinode := findOtherEnd(pair[0], pair[1])
+			if grid.Within(antinode, layout) {
+				layout[antinode.Y][antinode.X] = '#'
 				linearAntiNodes = append(linearAntiNodes, antinode)
 
 				from := pair[1]
 				center := antinode
 				for {
-					next := from.findOtherEnd(center)
-					if !next.isWithinBounds(layout) {
+					next := findOtherEnd(from, center)
+					if !grid.Within(next, layout) {
 						break
 					}
 					linearAntiNodes = append(linearAntiNodes, next)
@@ -153,8 +139,8 @@ func getLinearAntinodes(antennaInfo map[rune][]coordinate, layout [][]rune) []co
 	return linearAntiNodes
 }
 
-func getUniqueAntinodeCount(antinodes []coordinate) int {
-	uniqueAntinodes := make(map[coordinate]bool)
+func getUniqueAntinodeCount(antinodes []grid.Point) int {
+	uniqueAntinodes := make(map[grid.Point]bool)
 	for _, antinode := range antinodes {
 		uniqueAntinodes[antinode] = true
 	}
@@ -2,20 +2,53 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"iter"
 	"os"
 	"slices"
+	"strings"
+
+	"aoc/intmath"
+	"aoc/stream"
+	"aoc/trace"
+)
+
+var (
+	traceSteps  = flag.String("trace-steps", "", "write every block and file move as JSON Lines to `file`")
+	streamInput = flag.Bool("stream", false, "compact the disk map run by run instead of expanding it into blocks, for inputs too large for memory; part two still keeps a byte per free span")
 )
 
+// maxTraceBlocks is the largest disk snapshotted after every move, larger
+// disks are only snapshotted before and after compaction.
+const maxTraceBlocks = 1000
+
 func main() {
+	flag.Parse()
+
+	if *streamInput {
+		solveStreaming()
+		return
+	}
+
 	diskSpace, err := readInput()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	compressed, err := compress(diskSpace)
+	tracer, err := trace.Open(*traceSteps)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer func() {
+		if err := tracer.Close(); err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	compressed, err := compress(diskSpace, tracer)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -26,7 +59,7 @@ func main() {
 
 	chunks := splitIntoChunks(diskSpace)
 
-	compressedChunks, err := compressChunks(chunks)
+	compressedChunks, err := compressChunks(chunks, tracer)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -92,12 +125,16 @@ func readInput() ([]int, error) {
 	return diskSpace, nil
 }
 
-func compress(diskSpace []int) ([]int, error) {
+func compress(diskSpace []int, tracer trace.Tracer) ([]int, error) {
 	compressed := make([]int, 0)
 	next, stop := iter.Pull2(slices.Backward(diskSpace))
 	defer stop()
 
-	j := len(diskSpace) - 1
+	traceDisk(tracer, "part one", "disk before compaction", diskSpace)
+
+	// j is the last block taken from the back, everything from it on is free
+	j := len(diskSpace)
+blocks:
 	for i, cell := range diskSpace {
 		if i >= j {
 			break
@@ -114,13 +151,23 @@ func compress(diskSpace []int) ([]int, error) {
 			if !ok {
 				return nil, fmt.Errorf("unexpected end of disk space")
 			}
+			if k <= i {
+				// the blocks left of i are all in place
+				break blocks
+			}
 			if nextCell != -1 {
+				tracer.Decision("part one", "moving block %d of file %d to free block %d", k, nextCell, i)
 				compressed = append(compressed, nextCell)
+				if tracer.Enabled() && len(diskSpace) <= maxTraceBlocks {
+					traceDisk(tracer, "part one", fmt.Sprintf("moved block %d to %d", k, i), slices.Concat(compressed, diskSpace[i+1:k], slices.Repeat([]int{-1}, len(diskSpace)-k)))
+				}
 				break
 			}
 		}
 	}
 
+	traceDisk(tracer, "part one", "disk after compaction", compressed)
+
 	return compressed, nil
 }
 
@@ -159,10 +206,14 @@ func splitIntoChunks(diskSpace []int) []chunk {
 	return chunks
 }
 
-func compressChunks(chunks []chunk) ([]int, error) {
+func compressChunks(chunks []chunk, tracer trace.Tracer) ([]int, error) {
 	compressed := make([]chunk, 0)
 	compressed = append(compressed, chunks...)
 
+	if tracer.Enabled() {
+		traceDisk(tracer, "part two", "disk before compaction", expandChunks(compressed))
+	}
+
 	for _, fileChunk := range slices.Backward(chunks) {
 		if fileChunk.isFree() {
 			continue
@@ -177,9 +228,15 @@ func compressChunks(chunks []chunk) ([]int, error) {
 			return c.isFree() && c.size >= fileChunk.size
 		})
 		if firstSuitableChunkIndex == -1 || firstSuitableChunkIndex >= currentChunkIndex {
+			tracer.Decision("part two", "file %d (size %d) stays: no free span large enough to its left", fileChunk.fileID, fileChunk.size)
 			continue
 		}
 
+		if tracer.Enabled() {
+			tracer.Decision("part two", "moving file %d (size %d) from block %d to block %d",
+				fileChunk.fileID, fileChunk.size, chunkOffset(compressed, currentChunkIndex), chunkOffset(compressed, firstSuitableChunkIndex))
+		}
+
 		compressed[currentChunkIndex] = chunk{fileID: -1, size: fileChunk.size}
 
 		remainingSize := compressed[firstSuitableChunkIndex].size - fileChunk.size
@@ -188,14 +245,280 @@ func compressChunks(chunks []chunk) ([]int, error) {
 			newFreeChunk := chunk{fileID: -1, size: remainingSize}
 			compressed = slices.Insert(compressed, firstSuitableChunkIndex+1, newFreeChunk)
 		}
+
+		if tracer.Enabled() {
+			if diskSpace := expandChunks(compressed); len(diskSpace) <= maxTraceBlocks {
+				traceDisk(tracer, "part two", fmt.Sprintf("moved file %d", fileChunk.fileID), diskSpace)
+			}
+		}
 	}
 
+	diskSpace := expandChunks(compressed)
+	traceDisk(tracer, "part two", "disk after compaction", diskSpace)
+
+	return diskSpace, nil
+}
+
+func expandChunks(chunks []chunk) []int {
 	diskSpace := make([]int, 0)
-	for _, chunk := range compressed {
+	for _, chunk := range chunks {
 		for i := 0; i < chunk.size; i++ {
 			diskSpace = append(diskSpace, chunk.fileID)
 		}
 	}
 
-	return diskSpace, nil
+	return diskSpace
+}
+
+// chunkOffset returns the block the chunk at index starts at.
+func chunkOffset(chunks []chunk, index int) int {
+	offset := 0
+	for _, c := range chunks[:index] {
+		offset += c.size
+	}
+	return offset
+}
+
+const blockSymbols = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// traceDisk snapshots the disk as a single row in the puzzle's notation, with
+// file IDs above 9 written in base 62 modulo 62.
+func traceDisk(tracer trace.Tracer, scope, message string, diskSpace []int) {
+	if !tracer.Enabled() {
+		return
+	}
+
+	var row strings.Builder
+	for _, block := range diskSpace {
+		if block == -1 {
+			row.WriteByte('.')
+			continue
+		}
+		row.WriteByte(blockSymbols[block%len(blockSymbols)])
+	}
+
+	tracer.Snapshot(scope, message, trace.GridState{
+		Grid: []string{row.String()},
+		Info: map[string]any{"blocks": len(diskSpace), "checksum": calculateChecksum(diskSpace)},
+	})
+}
+
+// windowSize is the part of the disk map each cursor of the streaming solver
+// keeps in memory.
+const windowSize = 64 * 1024
+
+// diskMap reads the run lengths of the input in place. Run 2i is the size of
+// file i and run 2i+1 the free space after it.
+type diskMap struct {
+	file *os.File
+	runs int64
+	err  error
+}
+
+func openDiskMap() (*diskMap, error) {
+	file, err := os.Open("input.txt")
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	d := &diskMap{file: file, runs: info.Size()}
+	// ignore the trailing newline
+	last := d.cursor()
+	for d.runs > 0 && (last.At(d.runs-1) == '\n' || last.At(d.runs-1) == '\r') {
+		d.runs--
+	}
+	if err := last.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *diskMap) Close() error {
+	return d.file.Close()
+}
+
+// cursor returns a new window on the disk map, each walk through the map uses
+// its own so they don't evict each other's window.
+func (d *diskMap) cursor() *stream.Window {
+	info, _ := d.file.Stat()
+	return stream.NewWindow(d.file, info.Size(), windowSize)
+}
+
+// run returns the length of run i read through w, recording invalid digits.
+func (d *diskMap) run(w *stream.Window, i int64) int {
+	digit := w.At(i)
+	if err := w.Err(); err != nil && d.err == nil {
+		d.err = err
+	}
+	if (digit < '0' || digit > '9') && d.err == nil {
+		d.err = fmt.Errorf("invalid digit at %d: %q", i, digit)
+	}
+	return int(digit - '0')
+}
+
+func (d *diskMap) files() int64 {
+	return (d.runs + 1) / 2
+}
+
+// addFile adds the checksum of size blocks of file id starting at position.
+func addFile(checksum *intmath.Sum, id int64, position, size int) {
+	// position + (position+1) + ... + (position+size-1)
+	positions := size*position + size*(size-1)/2
+	checksum.AddProduct(int(id), positions)
+}
+
+// solveStreaming computes both checksums from the run lengths without
+// expanding the disk into blocks.
+func solveStreaming() {
+	disk, err := openDiskMap()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer disk.Close()
+
+	checksum := compressStreaming(disk)
+	if disk.err != nil {
+		fmt.Println(disk.err)
+		return
+	}
+	fmt.Printf("(Part one) Compressed disk checksum: %s\n", checksum.String())
+
+	checksum = compressFilesStreaming(disk)
+	if disk.err != nil {
+		fmt.Println(disk.err)
+		return
+	}
+	fmt.Printf("(Part two) Compressed disk checksum: %s\n", checksum.String())
+}
+
+// compressStreaming moves blocks like compress, walking the files from the
+// front and filling the free space with blocks taken from the back.
+func compressStreaming(disk *diskMap) intmath.Sum {
+	var checksum intmath.Sum
+	if disk.runs == 0 {
+		return checksum
+	}
+
+	front, back := disk.cursor(), disk.cursor()
+	last := disk.files() - 1
+	backRemaining := disk.run(back, 2*last)
+
+	position := 0
+	id := int64(0)
+	for ; id < last && disk.err == nil; id++ {
+		size := disk.run(front, 2*id)
+		addFile(&checksum, id, position, size)
+		position += size
+
+		free := disk.run(front, 2*id+1)
+		for free > 0 {
+			if backRemaining == 0 {
+				last--
+				if last == id {
+					break
+				}
+				backRemaining = disk.run(back, 2*last)
+				continue
+			}
+
+			moved := min(free, backRemaining)
+			addFile(&checksum, last, position, moved)
+			position += moved
+			free -= moved
+			backRemaining -= moved
+		}
+	}
+
+	if id == last {
+		addFile(&checksum, last, position, backRemaining)
+	}
+
+	return checksum
+}
+
+// spanCursor walks the free spans from the left, span i being the free space
+// after file i.
+type spanCursor struct {
+	window *stream.Window
+	span   int64
+	// start is the position of the first block of the span
+	start int
+}
+
+// compressFilesStreaming moves whole files like compressChunks. A file moves
+// to the leftmost span with enough room left, and since room only shrinks, the
+// leftmost span fitting a given size only ever moves right: one cursor per
+// file size finds every span in a single pass. Unlike part one this is not
+// constant memory: the room left in each span is kept, one byte per span, so
+// about half the size of the disk map. Empty files, which the puzzle inputs
+// don't have, would join the spans around them and are rejected.
+func compressFilesStreaming(disk *diskMap) intmath.Sum {
+	var checksum intmath.Sum
+	if disk.runs == 0 {
+		return checksum
+	}
+
+	spans := disk.runs / 2
+	room := make([]byte, spans)
+	totalBlocks := 0
+	scan := disk.cursor()
+	for i := range disk.runs {
+		size := disk.run(scan, i)
+		totalBlocks += size
+		if i%2 == 1 {
+			room[i/2] = byte(size)
+		}
+		if i%2 == 0 && size == 0 && disk.err == nil {
+			disk.err = fmt.Errorf("empty file %d: streaming part two needs files of at least one block", i/2)
+		}
+	}
+	if disk.err != nil {
+		return checksum
+	}
+
+	firstFile := disk.run(scan, 0)
+	cursors := make([]spanCursor, 10)
+	for size := range cursors {
+		cursors[size] = spanCursor{window: disk.cursor(), start: firstFile}
+	}
+
+	back := disk.cursor()
+	// position is the end of the file being moved
+	position := totalBlocks
+	if disk.runs%2 == 0 {
+		position -= disk.run(back, disk.runs-1)
+	}
+	for id := disk.files() - 1; id >= 0 && disk.err == nil; id-- {
+		size := disk.run(back, 2*id)
+		position -= size
+
+		c := &cursors[size]
+		for c.span < id && int(room[c.span]) < size {
+			c.start += disk.run(c.window, 2*c.span+1) + disk.run(c.window, 2*c.span+2)
+			c.span++
+		}
+
+		if c.span < id && size > 0 {
+			free := disk.run(c.window, 2*c.span+1)
+			addFile(&checksum, id, c.start+free-int(room[c.span]), size)
+			room[c.span] -= byte(size)
+		} else {
+			addFile(&checksum, id, position, size)
+		}
+
+		if id > 0 {
+			position -= disk.run(back, 2*id-1)
+		}
+	}
+
+	return checksum
 }
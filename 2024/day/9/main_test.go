@@ -0,0 +1,174 @@
+package main
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"aoc/trace"
+)
+
+// inDir runs the test in a temporary directory holding input as input.txt.
+func inDir(t *testing.T, input string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "input.txt"), []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// referenceChecksums compacts the blocks of a disk map the way the puzzle
+// describes it, one move at a time.
+func referenceChecksums(diskMap string) (int, int) {
+	var disk []int
+	for i, c := range diskMap {
+		id := i / 2
+		if i%2 == 1 {
+			id = -1
+		}
+		disk = append(disk, slices.Repeat([]int{id}, int(c-'0'))...)
+	}
+
+	blocks := slices.Clone(disk)
+	for free, last := 0, len(blocks)-1; ; {
+		for free < len(blocks) && blocks[free] != -1 {
+			free++
+		}
+		for last >= 0 && blocks[last] == -1 {
+			last--
+		}
+		if free >= last {
+			break
+		}
+		blocks[free], blocks[last] = blocks[last], -1
+	}
+	partOne := calculateChecksum(blocks)
+
+	blocks = disk
+	for id := (len(diskMap)+1)/2 - 1; id >= 0; id-- {
+		start := slices.Index(blocks, id)
+		if start < 0 {
+			continue
+		}
+		size := 0
+		for start+size < len(blocks) && blocks[start+size] == id {
+			size++
+		}
+		for i := 0; i+size <= start; i++ {
+			if slices.ContainsFunc(blocks[i:i+size], func(block int) bool { return block != -1 }) {
+				continue
+			}
+			for k := range size {
+				blocks[i+k], blocks[start+k] = id, -1
+			}
+			break
+		}
+	}
+	return partOne, calculateChecksum(blocks)
+}
+
+// emptyFile reports whether a file of diskMap has no blocks.
+func emptyFile(diskMap string) bool {
+	for i := 0; i < len(diskMap); i += 2 {
+		if diskMap[i] == '0' {
+			return true
+		}
+	}
+	return false
+}
+
+// randomDiskMap returns runs run lengths, up to maxRun each, files taking at
+// least minFile blocks.
+func randomDiskMap(random *rand.Rand, runs, maxRun, minFile int) string {
+	var b strings.Builder
+	for i := range runs {
+		low := 0
+		if i%2 == 0 {
+			low = minFile
+		}
+		b.WriteByte(byte('0' + low + random.IntN(maxRun-low+1)))
+	}
+	return b.String()
+}
+
+func TestModesAgree(t *testing.T) {
+	diskMaps := []string{
+		"2333133121414131402",
+		"12345",
+		// no free space at all
+		"1", "101", "1010101",
+		// free space left over at the end
+		"90909", "1919", "19",
+		// empty files
+		"0", "00", "1000001", "0909",
+		"",
+	}
+	random := rand.New(rand.NewPCG(9, 42))
+	for i := range 300 {
+		// puzzle inputs have no empty files, a few maps do
+		minFile := 1
+		if i%5 == 0 {
+			minFile = 0
+		}
+		diskMaps = append(diskMaps, randomDiskMap(random, 1+random.IntN(40), 1+random.IntN(9), minFile))
+	}
+
+	for _, diskMap := range diskMaps {
+		wantOne, wantTwo := referenceChecksums(diskMap)
+		inDir(t, diskMap)
+
+		diskSpace, err := readInput()
+		if err != nil {
+			t.Fatalf("%q: %v", diskMap, err)
+		}
+		compressed, err := compress(diskSpace, trace.Nop{})
+		if err != nil {
+			t.Fatalf("%q: %v", diskMap, err)
+		}
+		if got := calculateChecksum(compressed); got != wantOne {
+			t.Errorf("%q: in memory part one: got %d, want %d", diskMap, got, wantOne)
+		}
+		compressed, err = compressChunks(splitIntoChunks(diskSpace), trace.Nop{})
+		if err != nil {
+			t.Fatalf("%q: %v", diskMap, err)
+		}
+		if got := calculateChecksum(compressed); got != wantTwo {
+			t.Errorf("%q: in memory part two: got %d, want %d", diskMap, got, wantTwo)
+		}
+
+		disk, err := openDiskMap()
+		if err != nil {
+			t.Fatalf("%q: %v", diskMap, err)
+		}
+		partOne := compressStreaming(disk)
+		if disk.err != nil {
+			t.Fatalf("%q: %v", diskMap, disk.err)
+		}
+		if got := partOne.String(); got != strconv.Itoa(wantOne) {
+			t.Errorf("%q: streaming part one: got %s, want %d", diskMap, got, wantOne)
+		}
+		partTwo := compressFilesStreaming(disk)
+		disk.Close()
+		if emptyFile(diskMap) {
+			if disk.err == nil {
+				t.Errorf("%q: streaming part two: got no error for an empty file", diskMap)
+			}
+			continue
+		}
+		if got := partTwo.String(); got != strconv.Itoa(wantTwo) {
+			t.Errorf("%q: streaming part two: got %s, want %d", diskMap, got, wantTwo)
+		}
+	}
+}
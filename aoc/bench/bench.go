@@ -0,0 +1,208 @@
+// Package bench keeps a history of benchmark results keyed by git commit and
+// machine, so the timings of a commit can be compared with an earlier one.
+//
+//	{
+//	  "records": [
+//	    {
+//	      "commit": "edc372e...",
+//	      "machine": {"host": "box", "os": "linux", "arch": "amd64", "cpus": 8, "go": "go1.23.3"},
+//	      "time": "2024-12-20T10:00:00Z",
+//	      "runs": 5,
+//	      "days": {"2024/6": {"median": "1.2s", "min": "1.1s", "max": "1.4s"}}
+//	    }
+//	  ]
+//	}
+package bench
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"time"
+
+	"aoc/config"
+)
+
+// FileName is the default name of the history file in the repository root.
+const FileName = "bench.json"
+
+type Machine struct {
+	Host string `json:"host"`
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	CPUs int    `json:"cpus"`
+	Go   string `json:"go"`
+}
+
+// CurrentMachine describes the machine the command runs on.
+func CurrentMachine() Machine {
+	host, _ := os.Hostname()
+	return Machine{
+		Host: host,
+		OS:   runtime.GOOS,
+		Arch: runtime.GOARCH,
+		CPUs: runtime.NumCPU(),
+		Go:   runtime.Version(),
+	}
+}
+
+// Same reports whether timings taken on m and other can be compared. The Go
+// version is left out so a toolchain upgrade shows up as a change.
+func (m Machine) Same(other Machine) bool {
+	return m.Host == other.Host && m.OS == other.OS && m.Arch == other.Arch && m.CPUs == other.CPUs
+}
+
+func (m Machine) String() string {
+	return fmt.Sprintf("%s (%s/%s, %d CPUs, %s)", m.Host, m.OS, m.Arch, m.CPUs, m.Go)
+}
+
+// Timing summarises the runs of a day.
+type Timing struct {
+	Median config.Duration `json:"median"`
+	Min    config.Duration `json:"min"`
+	Max    config.Duration `json:"max"`
+}
+
+// Summarize returns the timing of the given run durations.
+func Summarize(durations []time.Duration) Timing {
+	if len(durations) == 0 {
+		return Timing{}
+	}
+
+	sorted := slices.Sorted(slices.Values(durations))
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+
+	return Timing{
+		Median: config.Duration(median),
+		Min:    config.Duration(sorted[0]),
+		Max:    config.Duration(sorted[len(sorted)-1]),
+	}
+}
+
+type Record struct {
+	Commit string `json:"commit"`
+	// Dirty is set when the working tree had uncommitted changes.
+	Dirty   bool      `json:"dirty,omitempty"`
+	Machine Machine   `json:"machine"`
+	Time    time.Time `json:"time"`
+	Runs    int       `json:"runs"`
+	// Days maps "year/day" to its timing.
+	Days map[string]Timing `json:"days"`
+}
+
+type History struct {
+	Records []Record `json:"records"`
+}
+
+// Load reads the history from path. A missing file yields an empty history.
+func Load(path string) (*History, error) {
+	history := &History{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading benchmark history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("error parsing benchmark history %s: %w", path, err)
+	}
+
+	return history, nil
+}
+
+func (h *History) Save(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("error writing benchmark history: %w", err)
+	}
+	return nil
+}
+
+// Latest returns the most recent record of commit taken on a machine like
+// machine, preferring records of a clean working tree.
+func (h *History) Latest(commit string, machine Machine) (Record, bool) {
+	var latest Record
+	found := false
+	for _, record := range h.Records {
+		if record.Commit != commit || !record.Machine.Same(machine) {
+			continue
+		}
+		better := !found ||
+			(latest.Dirty && !record.Dirty) ||
+			(latest.Dirty == record.Dirty && !record.Time.Before(latest.Time))
+		if better {
+			latest, found = record, true
+		}
+	}
+	return latest, found
+}
+
+// Change is the difference in median timing of a day between two records.
+// Before or After is zero when the day is missing from a record.
+type Change struct {
+	Day    string
+	Before time.Duration
+	After  time.Duration
+}
+
+// Percent returns how much slower After is than Before, negative for a
+// speedup.
+func (c Change) Percent() float64 {
+	if c.Before == 0 || c.After == 0 {
+		return 0
+	}
+	return (float64(c.After)/float64(c.Before) - 1) * 100
+}
+
+// Regressed reports whether the day got slower by more than threshold
+// percent.
+func (c Change) Regressed(threshold float64) bool {
+	return c.Before != 0 && c.After != 0 && c.Percent() > threshold
+}
+
+// Compare lists the changes of every day in either record, sorted by day.
+func Compare(before, after Record) []Change {
+	days := make(map[string]bool)
+	for day := range before.Days {
+		days[day] = true
+	}
+	for day := range after.Days {
+		days[day] = true
+	}
+
+	changes := make([]Change, 0, len(days))
+	for day := range days {
+		changes = append(changes, Change{
+			Day:    day,
+			Before: time.Duration(before.Days[day].Median),
+			After:  time.Duration(after.Days[day].Median),
+		})
+	}
+
+	slices.SortFunc(changes, func(a, b Change) int { return compareDays(a.Day, b.Day) })
+	return changes
+}
+
+// compareDays orders "year/day" keys numerically.
+func compareDays(a, b string) int {
+	var ay, ad, by, bd int
+	fmt.Sscanf(a, "%d/%d", &ay, &ad)
+	fmt.Sscanf(b, "%d/%d", &by, &bd)
+	if ay != by {
+		return ay - by
+	}
+	return ad - bd
+}
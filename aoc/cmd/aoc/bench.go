@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"aoc/bench"
+	"aoc/runner"
+)
+
+func benchCommand(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	runs := flags.Int("runs", 5, "number of timed runs per day")
+	record := flags.Bool("record", false, "append the timings to the history, keyed by commit and machine")
+	compare := flags.String("compare", "", "compare the timings with those recorded for git `ref`")
+	threshold := flags.Float64("threshold", 10, "fail when a day is more than `percent` slower than in the compared ref")
+	file := flags.String("file", "", "benchmark history `file` (default "+bench.FileName+" in the repository root)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: aoc bench [flags] [[year] day]")
+		flags.PrintDefaults()
+	}
+
+	positional, _, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if *runs < 1 {
+		return fmt.Errorf("-runs must be at least 1")
+	}
+
+	days, err := runner.List(env.root)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		day, err := env.day(positional)
+		if err != nil {
+			return err
+		}
+		days = []runner.Day{day}
+	}
+
+	historyPath := *file
+	if historyPath == "" {
+		historyPath = filepath.Join(env.root, bench.FileName)
+	}
+	history, err := bench.Load(historyPath)
+	if err != nil {
+		return err
+	}
+
+	machine := bench.CurrentMachine()
+	var baseline bench.Record
+	if *compare != "" {
+		commit, err := git(env.root, "rev-parse", "--verify", *compare+"^{commit}")
+		if err != nil {
+			return fmt.Errorf("unknown ref %q: %w", *compare, err)
+		}
+		var ok bool
+		baseline, ok = history.Latest(commit, machine)
+		if !ok {
+			return fmt.Errorf("no benchmark of %s (%.7s) recorded on this machine, check it out and run aoc bench -record", *compare, commit)
+		}
+	}
+
+	commit, err := git(env.root, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	status, err := git(env.root, "status", "--porcelain")
+	if err != nil {
+		return err
+	}
+
+	current := bench.Record{
+		Commit:  commit,
+		Dirty:   status != "",
+		Machine: machine,
+		Time:    time.Now().UTC(),
+		Runs:    *runs,
+		Days:    make(map[string]bench.Timing),
+	}
+	for _, day := range days {
+		timing, err := benchDay(ctx, env, day, *runs)
+		if err != nil {
+			return err
+		}
+		current.Days[day.String()] = timing
+		fmt.Printf("%-8v %12v  (min %v, max %v)\n", day,
+			round(time.Duration(timing.Median)), round(time.Duration(timing.Min)), round(time.Duration(timing.Max)))
+	}
+
+	if *record {
+		history.Records = append(history.Records, current)
+		if err := history.Save(historyPath); err != nil {
+			return err
+		}
+		dirty := ""
+		if current.Dirty {
+			dirty = " with uncommitted changes"
+		}
+		fmt.Printf("recorded %d days for %.7s%s on %v\n", len(current.Days), commit, dirty, machine)
+	}
+
+	if *compare == "" {
+		return nil
+	}
+
+	fmt.Printf("\ncompared with %s (%.7s, recorded %s)\n", *compare, baseline.Commit, baseline.Time.Local().Format(time.DateTime))
+	regressions := 0
+	for _, change := range bench.Compare(baseline, current) {
+		if change.After == 0 {
+			// not benchmarked this time
+			continue
+		}
+		verdict := ""
+		switch {
+		case change.Before == 0:
+			verdict = "new"
+		case change.Regressed(*threshold):
+			verdict = "REGRESSION"
+			regressions++
+		case change.Percent() < -*threshold:
+			verdict = "faster"
+		}
+		before := "-"
+		if change.Before != 0 {
+			before = round(change.Before).String()
+		}
+		fmt.Printf("%-8s %12s -> %12v  %+7.1f%%  %s\n", change.Day, before, round(change.After), change.Percent(), verdict)
+	}
+
+	if regressions > 0 {
+		return fmt.Errorf("%d days more than %g%% slower than %s", regressions, *threshold, *compare)
+	}
+	return nil
+}
+
+// benchDay builds the day once and times runs runs of it.
+func benchDay(ctx context.Context, env *environment, day runner.Day, runs int) (bench.Timing, error) {
+	binary, err := runner.Build(ctx, day)
+	if err != nil {
+		return bench.Timing{}, err
+	}
+
+	durations := make([]time.Duration, 0, runs)
+	for range runs {
+		result, err := runner.Exec(ctx, day, binary, env.options(day, "", nil, nil))
+		if err != nil {
+			return bench.Timing{}, err
+		}
+		durations = append(durations, result.Duration)
+	}
+
+	return bench.Summarize(durations), nil
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
+
+// git runs a git command in dir and returns its trimmed output.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("error running git: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffLines returns a line diff of before and after in unified style, without
+// hunk headers: unchanged lines are prefixed with two spaces, removed ones
+// with "- " and added ones with "+ ". Runs of unchanged lines further than
+// context lines away from a change are elided.
+func diffLines(before, after string, context int) string {
+	a := strings.Split(strings.TrimRight(before, "\n"), "\n")
+	b := strings.Split(strings.TrimRight(after, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	lines := make([]line, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j]})
+			j++
+		}
+	}
+
+	nearChange := make([]bool, len(lines))
+	for k, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		for n := max(0, k-context); n <= min(len(lines)-1, k+context); n++ {
+			nearChange[n] = true
+		}
+	}
+
+	var sb strings.Builder
+	elided := 0
+	for k, l := range lines {
+		if !nearChange[k] {
+			elided++
+			continue
+		}
+		if elided > 0 {
+			fmt.Fprintf(&sb, "  ... %d unchanged lines\n", elided)
+			elided = 0
+		}
+		fmt.Fprintf(&sb, "%c %s\n", l.op, l.text)
+	}
+	if elided > 0 {
+		fmt.Fprintf(&sb, "  ... %d unchanged lines\n", elided)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"aoc/config"
+	"aoc/puzzle"
+	"aoc/runner"
+)
+
+func fetchCommand(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	statement := flags.Bool("statement", false, "download the puzzle statement and extract its examples instead of the input")
+	force := flags.Bool("force", false, "download again even if already there, e.g. to get part two, and overwrite edited examples")
+	baseURL := flags.String("url", "", "base `URL` of the website (default the url in "+config.FileName+")")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: aoc fetch [flags] [year] day")
+		fmt.Fprintln(flags.Output(), "Downloads the input to the day's input.txt, or with -statement caches the statement")
+		fmt.Fprintln(flags.Output(), "for aoc show and writes every preformatted block of it to example.txt, example2.txt")
+		fmt.Fprintln(flags.Output(), "and so on. Delete the blocks that are not example inputs. Inputs and part two need")
+		fmt.Fprintln(flags.Output(), "the session cookie of the website in "+puzzle.SessionEnv+".")
+		flags.PrintDefaults()
+	}
+
+	positional, _, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	year, day, err := env.yearDay(positional)
+	if err != nil {
+		return err
+	}
+
+	url := *baseURL
+	if url == "" {
+		url = env.config.URL
+	}
+	client := puzzle.NewClient(url, os.Getenv(puzzle.SessionEnv))
+	dir := filepath.Join(env.root, strconv.Itoa(year), "day", strconv.Itoa(day))
+
+	if *statement {
+		return fetchStatement(ctx, env, client, dir, year, day, *force)
+	}
+	return fetchInput(ctx, env, client, dir, year, day, *force)
+}
+
+func fetchInput(ctx context.Context, env *environment, client *puzzle.Client, dir string, year, day int, force bool) error {
+	path := filepath.Join(dir, runner.DefaultInput)
+	if _, err := os.Stat(path); err == nil && !force {
+		fmt.Printf("%s already exists, use -force to download it again\n", env.relative(path))
+		return nil
+	}
+
+	input, err := client.Input(ctx, year, day)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, input, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s (%d lines)\n", env.relative(path), strings.Count(string(input), "\n"))
+	return nil
+}
+
+func fetchStatement(ctx context.Context, env *environment, client *puzzle.Client, dir string, year, day int, force bool) error {
+	cache := puzzle.DefaultCache()
+	page, err := cache.Statement(client.BaseURL, year, day)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err == nil && !force {
+		fmt.Printf("using the statement cached in %s, use -force to download it again\n", cache.MarkdownPath(client.BaseURL, year, day))
+	} else {
+		if page, err = client.Statement(ctx, year, day); err != nil {
+			return err
+		}
+		markdown := puzzle.Markdown(page, client.StatementURL(year, day))
+		if markdown == "" {
+			return fmt.Errorf("no puzzle statement found at %s", client.StatementURL(year, day))
+		}
+		if err := cache.Save(client.BaseURL, year, day, page, markdown); err != nil {
+			return err
+		}
+		fmt.Printf("cached the statement in %s\n", cache.MarkdownPath(client.BaseURL, year, day))
+	}
+
+	return writeExamples(env, dir, puzzle.Examples(page), force)
+}
+
+// writeExamples writes the examples of a statement to the day directory as
+// example.txt, example2.txt and so on. Examples edited since, other than by
+// trailing newlines, are kept unless force is set.
+func writeExamples(env *environment, dir string, examples []string, force bool) error {
+	if len(examples) == 0 {
+		fmt.Println("the statement has no examples")
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for i, example := range examples {
+		name := "example.txt"
+		if i > 0 {
+			name = fmt.Sprintf("example%d.txt", i+1)
+		}
+		path := filepath.Join(dir, name)
+
+		existing, err := os.ReadFile(path)
+		switch {
+		case err == nil && strings.TrimRight(string(existing), "\n") == strings.TrimRight(example, "\n"):
+			continue
+		case err == nil && !force:
+			fmt.Printf("kept %s, which differs from example %d, use -force to overwrite it\n", env.relative(path), i+1)
+			continue
+		case err != nil && !errors.Is(err, os.ErrNotExist):
+			return err
+		}
+
+		if err := os.WriteFile(path, []byte(example), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %s (%d lines)\n", env.relative(path), strings.Count(example, "\n"))
+	}
+	return nil
+}
+
+// relative returns path relative to the repository root when it is inside it.
+func (e *environment) relative(path string) string {
+	rel, err := filepath.Rel(e.root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
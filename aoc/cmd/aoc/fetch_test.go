@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aoc/puzzle"
+)
+
+func readExample(t *testing.T, dir, name string) string {
+	t.Helper()
+	example, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(example)
+}
+
+func TestWriteExamples(t *testing.T) {
+	env := &environment{root: t.TempDir()}
+	dir := filepath.Join(env.root, "1999", "day", "1")
+	examples := []string{"1 2\n", "3 4\n"}
+
+	if err := writeExamples(env, dir, examples, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := readExample(t, dir, "example.txt"); got != examples[0] {
+		t.Errorf("example.txt: got %q, want %q", got, examples[0])
+	}
+	if got := readExample(t, dir, "example2.txt"); got != examples[1] {
+		t.Errorf("example2.txt: got %q, want %q", got, examples[1])
+	}
+
+	// an edit is kept, a difference in trailing newlines is no edit
+	edited := "1 2\n5 6\n"
+	if err := os.WriteFile(filepath.Join(dir, "example.txt"), []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "example2.txt"), []byte("3 4"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeExamples(env, dir, examples, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := readExample(t, dir, "example.txt"); got != edited {
+		t.Errorf("edited example.txt: got %q, want it kept as %q", got, edited)
+	}
+	if got := readExample(t, dir, "example2.txt"); got != "3 4" {
+		t.Errorf("example2.txt without a newline: got %q, want it untouched", got)
+	}
+
+	if err := writeExamples(env, dir, examples, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := readExample(t, dir, "example.txt"); got != examples[0] {
+		t.Errorf("edited example.txt with force: got %q, want %q", got, examples[0])
+	}
+}
+
+func TestFetchStatement(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	page, err := os.ReadFile("../../puzzle/testdata/statement.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// newStub serves page as the statement of 1999/1, counting the fetches
+	newStub := func(fetches *int) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/1999/day/1" {
+				http.NotFound(w, r)
+				return
+			}
+			*fetches++
+			w.Write(page)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	var fetches, otherFetches int
+
+	env := &environment{root: t.TempDir()}
+	dir := filepath.Join(env.root, "1999", "day", "1")
+	client := puzzle.NewClient(newStub(&fetches).URL, "")
+	ctx := context.Background()
+
+	if err := fetchStatement(ctx, env, client, dir, 1999, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readExample(t, dir, "example.txt"), "1   2\n3   4\n"; got != want {
+		t.Errorf("example.txt: got %q, want %q", got, want)
+	}
+	if got, want := readExample(t, dir, "example2.txt"), "5 < 6\n"; got != want {
+		t.Errorf("example2.txt: got %q, want %q", got, want)
+	}
+	markdown, err := os.ReadFile(puzzle.DefaultCache().MarkdownPath(client.BaseURL, 1999, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := puzzle.Markdown(string(page), client.StatementURL(1999, 1)); string(markdown) != want {
+		t.Errorf("cached Markdown: got\n%s\nwant\n%s", markdown, want)
+	}
+
+	// the cached statement is used until forced
+	if err := fetchStatement(ctx, env, client, dir, 1999, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 1 {
+		t.Errorf("got %d fetches without force, want 1", fetches)
+	}
+	if err := fetchStatement(ctx, env, client, dir, 1999, 1, true); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Errorf("got %d fetches with force, want 2", fetches)
+	}
+
+	// the cache is per website, a stub on another port doesn't share it
+	other := puzzle.NewClient(newStub(&otherFetches).URL, "")
+	if err := fetchStatement(ctx, env, other, dir, 1999, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 || otherFetches != 1 {
+		t.Errorf("another website: got %d and %d fetches, want 2 and 1", fetches, otherFetches)
+	}
+
+	if err := fetchStatement(ctx, env, client, dir, 1999, 2, false); err == nil {
+		t.Error("fetching a missing statement: got no error")
+	}
+}
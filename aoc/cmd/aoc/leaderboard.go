@@ -0,0 +1,190 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"aoc/config"
+	"aoc/puzzle"
+)
+
+func leaderboardCommand(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("leaderboard", flag.ContinueOnError)
+	year := flags.Int("year", env.config.Year, "year of the event")
+	day := flags.Int("day", 0, "show when the members earned the stars of `day`, relative to its release")
+	ttl := flags.Duration("ttl", 15*time.Minute, "reuse a leaderboard fetched less than this long ago, the website asks for at most a request every 15 minutes")
+	baseURL := flags.String("url", "", "base `URL` of the website (default the url in "+config.FileName+")")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: aoc leaderboard [flags] id")
+		fmt.Fprintln(flags.Output(), "Private leaderboards need the session cookie of one of their members in "+puzzle.SessionEnv+".")
+		flags.PrintDefaults()
+	}
+
+	positional, _, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("expected a leaderboard id, got %q", strings.Join(positional, " "))
+	}
+	id := positional[0]
+	if _, err := strconv.Atoi(id); err != nil {
+		return fmt.Errorf("invalid leaderboard id %q", id)
+	}
+	if *day < 0 || *day > puzzle.Days(*year) {
+		return fmt.Errorf("invalid day %d, the %d event has %d", *day, *year, puzzle.Days(*year))
+	}
+
+	url := *baseURL
+	if url == "" {
+		url = env.config.URL
+	}
+	client := puzzle.NewClient(url, os.Getenv(puzzle.SessionEnv))
+	leaderboard, fetched, err := loadLeaderboard(ctx, puzzle.DefaultCache(), client, *year, id, *ttl)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Private leaderboard %s of %d, fetched %v ago\n\n", id, *year, time.Since(fetched).Round(time.Second))
+	if *day > 0 {
+		printDayTimes(leaderboard, *year, *day)
+		return nil
+	}
+	return printStandings(leaderboard, *year, time.Now())
+}
+
+// loadLeaderboard returns the private leaderboard id of year and when it was
+// fetched, downloading it only if the cache has none younger than ttl from the
+// website of client.
+func loadLeaderboard(ctx context.Context, cache puzzle.Cache, client *puzzle.Client, year int, id string, ttl time.Duration) (puzzle.Leaderboard, time.Time, error) {
+	data, fetched, ok := cache.Leaderboard(client.BaseURL, year, id, ttl)
+	if !ok {
+		var err error
+		if data, err = client.Leaderboard(ctx, year, id); err != nil {
+			return puzzle.Leaderboard{}, time.Time{}, err
+		}
+		if _, err := puzzle.ParseLeaderboard(data); err != nil {
+			return puzzle.Leaderboard{}, time.Time{}, err
+		}
+		if err := cache.SaveLeaderboard(client.BaseURL, year, id, data); err != nil {
+			return puzzle.Leaderboard{}, time.Time{}, err
+		}
+		fetched = time.Now()
+	}
+
+	leaderboard, err := puzzle.ParseLeaderboard(data)
+	if err != nil {
+		return puzzle.Leaderboard{}, time.Time{}, err
+	}
+	return leaderboard, fetched, nil
+}
+
+// printStandings prints the members by local score with their stars for every
+// released day.
+func printStandings(leaderboard puzzle.Leaderboard, year int, now time.Time) error {
+	days := 0
+	for days < puzzle.Days(year) && !now.Before(puzzle.Release(year, days+1)) {
+		days++
+	}
+	if days == 0 {
+		return fmt.Errorf("the %d event has not started yet", year)
+	}
+
+	// two header lines number the days: tens then units
+	var tens, units strings.Builder
+	for day := 1; day <= days; day++ {
+		tens.WriteString(strings.TrimPrefix(strconv.Itoa(day/10), "0"))
+		if day < 10 {
+			tens.WriteByte(' ')
+		}
+		units.WriteString(strconv.Itoa(day % 10))
+	}
+	fmt.Printf("%10s %s\n%10s %s\n", "", tens.String(), "", units.String())
+
+	rank := 0
+	members := leaderboard.Ranked()
+	for i, member := range members {
+		if i == 0 || member.LocalScore != members[i-1].LocalScore {
+			rank = i + 1
+		}
+
+		var stars strings.Builder
+		for day := 1; day <= days; day++ {
+			_, first := member.Star(day, 1)
+			_, second := member.Star(day, 2)
+			switch {
+			case second:
+				stars.WriteByte('*')
+			case first:
+				stars.WriteByte('+')
+			default:
+				stars.WriteByte('.')
+			}
+		}
+		fmt.Printf("%3d) %5d %s %3d  %s\n", rank, member.LocalScore, stars.String(), member.Stars, member.DisplayName())
+	}
+
+	fmt.Println("\n* both stars, + first star only")
+	return nil
+}
+
+// printDayTimes prints how long after the release of day every member earned
+// its stars, fastest first.
+func printDayTimes(leaderboard puzzle.Leaderboard, year, day int) {
+	release := puzzle.Release(year, day)
+	fmt.Printf("Day %d, released %s\n\n", day, release.Local().Format(time.DateTime))
+
+	type times struct {
+		member        puzzle.Member
+		first, second time.Duration
+	}
+	var rows []times
+	for _, member := range leaderboard.Members {
+		first, ok := member.Star(day, 1)
+		if !ok {
+			continue
+		}
+		row := times{member: member, first: first.Sub(release), second: -1}
+		if second, ok := member.Star(day, 2); ok {
+			row.second = second.Sub(release)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		fmt.Println("nobody has a star yet")
+		return
+	}
+
+	slices.SortFunc(rows, func(a, b times) int {
+		// members without the second star go last
+		if (a.second < 0) != (b.second < 0) {
+			if a.second < 0 {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(cmp.Compare(a.second, b.second), cmp.Compare(a.first, b.first), a.member.ID-b.member.ID)
+	})
+
+	fmt.Printf("%5s %10s %10s %10s\n", "", "part one", "part two", "delta")
+	for i, row := range rows {
+		second, delta := "-", "-"
+		if row.second >= 0 {
+			second, delta = clock(row.second), clock(row.second-row.first)
+		}
+		fmt.Printf("%3d) %10s %10s %10s  %s\n", i+1, clock(row.first), second, delta, row.member.DisplayName())
+	}
+}
+
+// clock formats d as hours, minutes and seconds, the hours growing past 24.
+func clock(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
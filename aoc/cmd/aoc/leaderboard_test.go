@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"aoc/puzzle"
+)
+
+// newLeaderboardStub serves the leaderboard in the puzzle testdata as the
+// private leaderboard 42 of 1999 to the holder of the session cookie, and
+// counts the requests for it.
+func newLeaderboardStub(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+	data, err := os.ReadFile("../../puzzle/testdata/leaderboard.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1999/leaderboard/private/view/42.json" {
+			http.NotFound(w, r)
+			return
+		}
+		*requests++
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "s3cr3t" {
+			// the website redirects to the page of the leaderboards instead
+			w.Write([]byte("<!DOCTYPE html><html></html>"))
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLoadLeaderboard(t *testing.T) {
+	cache := puzzle.Cache{Dir: t.TempDir()}
+	ctx := context.Background()
+	var requests, otherRequests int
+	client := puzzle.NewClient(newLeaderboardStub(t, &requests).URL, "s3cr3t")
+	other := puzzle.NewClient(newLeaderboardStub(t, &otherRequests).URL, "s3cr3t")
+
+	load := func(client *puzzle.Client, ttl time.Duration) {
+		t.Helper()
+		leaderboard, _, err := loadLeaderboard(ctx, cache, client, 1999, "42", ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(leaderboard.Members) != 6 {
+			t.Fatalf("got %d members, want 6", len(leaderboard.Members))
+		}
+	}
+
+	load(client, time.Hour)
+	load(client, time.Hour)
+	if requests != 1 {
+		t.Errorf("within the ttl: got %d requests, want 1", requests)
+	}
+	// the cache is per website, a stub on another port doesn't share it
+	load(other, time.Hour)
+	if requests != 1 || otherRequests != 1 {
+		t.Errorf("another website: got %d and %d requests, want 1 and 1", requests, otherRequests)
+	}
+	load(client, 0)
+	if requests != 2 {
+		t.Errorf("past the ttl: got %d requests, want 2", requests)
+	}
+
+	// a logged out answer is an error and not cached
+	loggedOut := puzzle.NewClient(client.BaseURL, "stale")
+	if _, _, err := loadLeaderboard(ctx, cache, loggedOut, 1999, "42", 0); err == nil {
+		t.Error("logged out: got no error")
+	}
+	load(client, time.Hour)
+	if requests != 3 {
+		t.Errorf("after a logged out answer: got %d requests, want 3", requests)
+	}
+	if _, _, err := loadLeaderboard(ctx, cache, puzzle.NewClient(client.BaseURL, ""), 1999, "42", 0); !errors.Is(err, puzzle.ErrNoSession) {
+		t.Errorf("without a session: got %v, want %v", err, puzzle.ErrNoSession)
+	}
+}
+
+// captureStdout returns what f prints.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		output <- string(b)
+	}()
+	f()
+	w.Close()
+	return <-output
+}
+
+func TestPrintStandings(t *testing.T) {
+	var requests int
+	client := puzzle.NewClient(newLeaderboardStub(t, &requests).URL, "s3cr3t")
+	leaderboard, _, err := loadLeaderboard(context.Background(), puzzle.Cache{Dir: t.TempDir()}, client, 1999, "42", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// on the fourth day, members on the same score share a rank
+	now := puzzle.Release(1999, 4).Add(time.Minute)
+	standings := captureStdout(t, func() { err = printStandings(leaderboard, 1999, now) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"  1)    30 **+.   5  Cy",
+		"  1)    30 **..   4  (anonymous user #2)",
+		"  1)    30 **..   4  Ada",
+		"  4)    10 *...   2  Dee",
+		"  4)    10 *...   2  Eve",
+		"  6)     0 ....   0  Finn",
+	}
+	lines := strings.Split(standings, "\n")
+	if len(lines) < 2+len(want) {
+		t.Fatalf("got standings\n%s", standings)
+	}
+	for i, line := range lines[2 : 2+len(want)] {
+		if line != want[i] {
+			t.Errorf("line %d: got %q, want %q", i+3, line, want[i])
+		}
+	}
+
+	if err := printStandings(leaderboard, 1999, puzzle.Release(1999, 1).Add(-time.Second)); err == nil {
+		t.Error("before the event: got no error")
+	}
+}
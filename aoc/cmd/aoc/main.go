@@ -0,0 +1,204 @@
+// Command aoc builds and runs the daily solutions in this repository.
+//
+//	aoc run [-input file] [-param key=value]... [-trace-steps file] [-stream] [year] day [-- day flags]
+//	aoc replay [-scope name] file
+//	aoc bench [-runs n] [-record] [-compare ref] [-threshold percent] [[year] day]
+//	aoc shrink [-input file] [-against flags | -ref ref] [-match regexp] [-o file] [year] day
+//	aoc watch [-interval d] [-param key=value]... [year] day [-- day flags]
+//	aoc serve [-addr host:port] [-max-input bytes] [-timeout d]
+//	aoc tui [year]
+//	aoc fetch [-statement] [-force] [-url base] [year] day
+//	aoc show [-markdown] [-url base] [year] day
+//	aoc leaderboard [-year y] [-day d] [-ttl d] [-url base] id
+//
+// Runner defaults and per-day parameters are read from aoc.json in the
+// repository root, see package config. aoc fetch and aoc leaderboard read the
+// session cookie of the website from AOC_SESSION, see package puzzle.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"aoc/config"
+	"aoc/runner"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, env *environment, args []string) error
+}
+
+var commands = []command{
+	{name: "run", usage: "run a day and print its answers", run: runCommand},
+	{name: "replay", usage: "step through a trace written by aoc run -trace-steps", run: replayCommand},
+	{name: "bench", usage: "time the days, record the timings per commit and compare them with an earlier commit", run: benchCommand},
+	{name: "shrink", usage: "reduce an input on which a day fails or disagrees with another implementation", run: shrinkCommand},
+	{name: "watch", usage: "re-run the tests of a day and the day on its examples and input whenever its files change", run: watchCommand},
+	{name: "serve", usage: "serve the solutions over a local HTTP/JSON API", run: serveCommand},
+	{name: "tui", usage: "pick, run and visualize days in a full-screen terminal interface", run: tuiCommand},
+	{name: "fetch", usage: "download a day's input, or its statement and examples", run: fetchCommand},
+	{name: "show", usage: "print a fetched puzzle statement", run: showCommand},
+	{name: "leaderboard", usage: "show the standings and star times of a private leaderboard", run: leaderboardCommand},
+}
+
+// environment is shared by all commands.
+type environment struct {
+	root   string
+	config config.Config
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		env, err := loadEnvironment()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		err = cmd.run(ctx, env, os.Args[2:])
+		stop()
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: aoc <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, cmd.usage)
+	}
+}
+
+func loadEnvironment() (*environment, error) {
+	start := os.Getenv("AOC_ROOT")
+	if start == "" {
+		start = "."
+	}
+
+	root, err := runner.FindRoot(start)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load(filepath.Join(root, config.FileName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &environment{root: root, config: cfg}, nil
+}
+
+// day resolves the positional "[year] day" arguments to a day with a solution.
+func (e *environment) day(args []string) (runner.Day, error) {
+	year, day, err := e.yearDay(args)
+	if err != nil {
+		return runner.Day{}, err
+	}
+
+	return runner.Find(e.root, year, day)
+}
+
+// yearDay parses the positional "[year] day" arguments.
+func (e *environment) yearDay(args []string) (year, day int, err error) {
+	year = e.config.Year
+	var dayArg string
+	switch len(args) {
+	case 1:
+		dayArg = args[0]
+	case 2:
+		if year, err = strconv.Atoi(args[0]); err != nil {
+			return 0, 0, fmt.Errorf("invalid year %q", args[0])
+		}
+		dayArg = args[1]
+	default:
+		return 0, 0, fmt.Errorf("expected [year] day, got %q", strings.Join(args, " "))
+	}
+
+	if day, err = strconv.Atoi(dayArg); err != nil {
+		return 0, 0, fmt.Errorf("invalid day %q", dayArg)
+	}
+	return year, day, nil
+}
+
+// options builds the run options for a day from the config and the common
+// -input and -param flags.
+func (e *environment) options(day runner.Day, input string, params []string, args []string) runner.Options {
+	if input != "" && !filepath.IsAbs(input) {
+		// prefer paths relative to where the command runs, fall back to the day directory
+		if _, err := os.Stat(input); err == nil {
+			input, _ = filepath.Abs(input)
+		}
+	}
+
+	return runner.Options{
+		Input:   input,
+		Params:  append(e.config.Params(day.Year, day.Day, input), params...),
+		Args:    args,
+		Timeout: time.Duration(e.config.Timeout),
+	}
+}
+
+// parseArgs parses flags that may be interleaved with positional arguments.
+// Arguments after "--" are returned untouched as passthrough.
+func parseArgs(fs *flag.FlagSet, args []string) (positional, passthrough []string, err error) {
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, nil, err
+		}
+
+		rest := fs.Args()
+		consumed := len(args) - len(rest)
+		if consumed > 0 && args[consumed-1] == "--" {
+			return positional, rest, nil
+		}
+		if len(rest) == 0 {
+			return positional, nil, nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// multiFlag collects the values of a repeated flag.
+type multiFlag []string
+
+func (m *multiFlag) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *multiFlag) Set(value string) error {
+	*m = append(*m, value)
+	return nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"aoc/trace"
+)
+
+type replay struct {
+	name   string
+	term   *terminal
+	events []trace.Event
+	// snapshots holds for each event the index of the latest snapshot of its
+	// scope at or before it, -1 if there is none
+	snapshots []int
+	index     int
+	// column is the horizontal scroll of wide states
+	column int
+}
+
+func replayCommand(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	scope := flags.String("scope", "", "only replay the events of `scope`, e.g. \"part two\"")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: aoc replay [flags] file")
+		flags.PrintDefaults()
+	}
+	positional, _, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	events, err := trace.ReadFile(positional[0])
+	if err != nil {
+		return err
+	}
+	if *scope != "" {
+		events = slices.DeleteFunc(events, func(e trace.Event) bool { return e.Scope != *scope })
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("no events to replay in %s", positional[0])
+	}
+
+	r := &replay{name: positional[0], events: events, snapshots: make([]int, len(events))}
+	latest := map[string]int{}
+	for i, event := range events {
+		if event.Kind == trace.KindSnapshot {
+			latest[event.Scope] = i
+		}
+		r.snapshots[i] = -1
+		if snapshot, ok := latest[event.Scope]; ok {
+			r.snapshots[i] = snapshot
+		}
+	}
+
+	r.term, err = openTerminal()
+	if err != nil {
+		return err
+	}
+	defer r.term.Close()
+
+	for {
+		r.draw()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case k := <-r.term.keys:
+			if quit := r.handleKey(k); quit {
+				return nil
+			}
+		}
+	}
+}
+
+func (r *replay) handleKey(k key) (quit bool) {
+	last := len(r.events) - 1
+	switch k {
+	case "q", keyEscape:
+		return true
+	case keyLeft, "h":
+		r.index = max(r.index-1, 0)
+	case keyRight, "l":
+		r.index = min(r.index+1, last)
+	case keyUp, "k":
+		r.index = max(r.index-10, 0)
+	case keyDown, "j":
+		r.index = min(r.index+10, last)
+	case "g":
+		r.index = 0
+	case "G":
+		r.index = last
+	case "n":
+		r.index = r.find(trace.KindDecision, 1)
+	case "N":
+		r.index = r.find(trace.KindDecision, -1)
+	case "s":
+		r.index = r.find(trace.KindSnapshot, 1)
+	case "S":
+		r.index = r.find(trace.KindSnapshot, -1)
+	case "<":
+		r.column = max(r.column-20, 0)
+	case ">":
+		r.column += 20
+	}
+	return false
+}
+
+// find returns the next event of kind in direction step, or the current one
+// if there is none.
+func (r *replay) find(kind trace.Kind, step int) int {
+	for i := r.index + step; i >= 0 && i < len(r.events); i += step {
+		if r.events[i].Kind == kind {
+			return i
+		}
+	}
+	return r.index
+}
+
+func (r *replay) draw() {
+	rows, cols := r.term.size()
+	event := r.events[r.index]
+
+	lines := []string{
+		truncate(fmt.Sprintf("\x1b[1m%s\x1b[0m · %d/%d · #%d %s · %s", r.name, r.index+1, len(r.events), event.Seq, event.Kind, event.Scope), cols),
+		truncate(event.Message, cols),
+		"",
+	}
+
+	// decisions are shown on top of the state they were taken in
+	if snapshot := r.snapshots[r.index]; snapshot != -1 {
+		if snapshot != r.index {
+			lines = append(lines, truncate(fmt.Sprintf("\x1b[2mstate of #%d: %s\x1b[0m", r.events[snapshot].Seq, r.events[snapshot].Message), cols))
+		}
+		for _, line := range stateLines(r.events[snapshot].State) {
+			if len(lines) >= rows-1 {
+				break
+			}
+			lines = append(lines, truncate(skipColumns(line, r.column), cols))
+		}
+	}
+
+	for len(lines) < rows-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, truncate("\x1b[2m←/→ step  ↑/↓ step 10  n/N decision  s/S snapshot  g/G first/last  </> scroll  q quit\x1b[0m", cols))
+	r.term.draw(lines)
+}
+
+// stateLines renders grid states as their extra values followed by the rows,
+// anything else as indented JSON.
+func stateLines(state any) []string {
+	if object, ok := state.(map[string]any); ok {
+		if grid, ok := object["grid"].([]any); ok {
+			lines := make([]string, 0, len(grid)+2)
+			if info, ok := object["info"].(map[string]any); ok {
+				values := make([]string, 0, len(info))
+				for _, name := range slices.Sorted(maps.Keys(info)) {
+					value, _ := json.Marshal(info[name])
+					values = append(values, fmt.Sprintf("%s=%s", name, value))
+				}
+				lines = append(lines, strings.Join(values, "  "), "")
+			}
+			for _, row := range grid {
+				lines = append(lines, fmt.Sprint(row))
+			}
+			return lines
+		}
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return strings.Split(string(data), "\n")
+}
+
+func skipColumns(s string, n int) string {
+	runes := []rune(s)
+	return string(runes[min(n, len(runes)):])
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"aoc/runner"
+)
+
+func runCommand(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	input := flags.String("input", "", "puzzle input file (default the day's input.txt)")
+	var params multiFlag
+	flags.Var(&params, "param", "set a day parameter as `key=value` (repeatable)")
+	traceSteps := flags.String("trace-steps", "", "write the day's steps as JSON Lines to `file`, see aoc replay")
+	streamInput := flags.Bool("stream", false, "use the day's streaming solvers, for inputs too large for memory")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: aoc run [flags] [year] day [-- day flags]")
+		flags.PrintDefaults()
+	}
+
+	positional, passthrough, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	day, err := env.day(positional)
+	if err != nil {
+		return err
+	}
+
+	if *traceSteps != "" {
+		if !day.Traceable() {
+			return fmt.Errorf("%v does not support tracing", day)
+		}
+		// the day runs in its own directory
+		path, err := filepath.Abs(*traceSteps)
+		if err != nil {
+			return err
+		}
+		passthrough = append(slices.Clip(passthrough), "-trace-steps", path)
+	}
+
+	if *streamInput {
+		if !day.Streamable() {
+			return fmt.Errorf("%v has no streaming solvers", day)
+		}
+		passthrough = append(slices.Clip(passthrough), "-stream")
+	}
+
+	result, err := runner.Run(ctx, day, env.options(day, *input, params, passthrough))
+	fmt.Print(result.Output)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v finished in %v\n", day, result.Duration.Round(time.Microsecond))
+	if *traceSteps != "" {
+		fmt.Printf("trace written to %s\n", *traceSteps)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"aoc/server"
+)
+
+func serveCommand(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	maxInput := flags.Int64("max-input", 10<<20, "largest accepted input in bytes")
+	timeout := flags.Duration("timeout", 30*time.Second, "time limit for a single run")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: aoc serve [flags]")
+		flags.PrintDefaults()
+	}
+
+	if _, _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:    *addr,
+		Handler: server.New(env.root, env.config, server.Options{MaxInputSize: *maxInput, Timeout: *timeout}),
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("serving on http://%s\n", *addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
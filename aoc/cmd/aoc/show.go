@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"aoc/config"
+	"aoc/puzzle"
+)
+
+func showCommand(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("show", flag.ContinueOnError)
+	markdown := flags.Bool("markdown", false, "print the statement as Markdown even on a terminal")
+	baseURL := flags.String("url", "", "base `URL` of the website the statement was fetched from (default the url in "+config.FileName+")")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: aoc show [flags] [year] day")
+		flags.PrintDefaults()
+	}
+
+	positional, _, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	year, day, err := env.yearDay(positional)
+	if err != nil {
+		return err
+	}
+
+	url := *baseURL
+	if url == "" {
+		url = env.config.URL
+	}
+	url = strings.TrimSuffix(url, "/")
+
+	cache := puzzle.DefaultCache()
+	page, err := cache.Statement(url, year, day)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no statement of %d/%d cached from %s, run aoc fetch -statement %d %d", year, day, url, year, day)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *markdown || !isTerminal(os.Stdout) {
+		data, err := os.ReadFile(cache.MarkdownPath(url, year, day))
+		if err != nil {
+			return fmt.Errorf("error reading cached statement: %w", err)
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	fmt.Print(puzzle.Terminal(page))
+	return nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
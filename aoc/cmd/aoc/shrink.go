@@ -0,0 +1,270 @@
+package main
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"aoc/runner"
+	"aoc/shrink"
+)
+
+func shrinkCommand(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("shrink", flag.ContinueOnError)
+	input := flags.String("input", "", "puzzle input file to shrink (default the day's input.txt)")
+	var params multiFlag
+	flags.Var(&params, "param", "set a day parameter as `key=value` (repeatable)")
+	against := flags.String("against", "", "shrink an input on which the day disagrees with itself run with the extra `flags`, e.g. -bigint")
+	ref := flags.String("ref", "", "shrink an input on which the day disagrees with its implementation at git `ref`")
+	match := flags.String("match", "", "count runs whose output matches `regexp` as failing, with -against or -ref only disagreements where it matches")
+	timeout := flags.Duration("timeout", 10*time.Second, "limit a single run of the day")
+	output := flags.String("o", "", "write the shrunk input to `file` instead of standard output")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: aoc shrink [flags] [year] day")
+		fmt.Fprintln(flags.Output(), "Without -against or -ref, shrinks an input on which the day fails the same way, or prints a -match.")
+		flags.PrintDefaults()
+	}
+
+	positional, _, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if *against != "" && *ref != "" {
+		return fmt.Errorf("-against and -ref are exclusive")
+	}
+
+	day, err := env.day(positional)
+	if err != nil {
+		return err
+	}
+
+	var matchRe *regexp.Regexp
+	if *match != "" {
+		if matchRe, err = regexp.Compile(*match); err != nil {
+			return fmt.Errorf("invalid -match: %w", err)
+		}
+	}
+
+	options := env.options(day, *input, params, nil)
+	options.Timeout = *timeout
+	data, err := readDayInput(day, options.Input)
+	if err != nil {
+		return err
+	}
+
+	binary, err := runner.Build(ctx, day)
+	if err != nil {
+		return err
+	}
+	run := func(ctx context.Context, binary string, input string, args []string) shrinkOutcome {
+		options := options
+		options.InputData = []byte(input)
+		options.Args = args
+		result, err := runner.Exec(ctx, day, binary, options)
+		return shrinkOutcome{result: result, err: err}
+	}
+
+	var test shrink.Test
+	switch {
+	case *against != "" || *ref != "":
+		otherBinary, otherArgs := binary, strings.Fields(*against)
+		if *ref != "" {
+			dir, err := os.MkdirTemp("", "aoc-shrink-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(dir)
+
+			if otherBinary, err = buildAtRef(ctx, env.root, day, *ref, dir); err != nil {
+				return err
+			}
+		}
+
+		test = func(ctx context.Context, input string) (bool, error) {
+			a := run(ctx, binary, input, nil)
+			b := run(ctx, otherBinary, input, otherArgs)
+			if a.timedOut() || b.timedOut() || a.agrees(b) {
+				return false, nil
+			}
+			return matchRe == nil || matchRe.MatchString(a.String()+"\n"+b.String()), nil
+		}
+
+	default:
+		// days print the errors of inputs they can't parse rather than
+		// exiting, so any failure would do, and the reduction would drift to
+		// a parse error: without -match, it has to be the input's own failure
+		failure := run(ctx, binary, data, nil).failure()
+		test = func(ctx context.Context, input string) (bool, error) {
+			outcome := run(ctx, binary, input, nil)
+			if matchRe != nil {
+				// days report some errors on their output and carry on
+				return matchRe.MatchString(outcome.String()), nil
+			}
+			return outcome.err != nil && outcome.failure() == failure, nil
+		}
+	}
+
+	start := time.Now()
+	shrunk, stats, err := shrink.Minimize(ctx, data, shrink.ForDay(day.Year, day.Day), test)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "shrunk %v input from %d to %d bytes, %d lines, in %d runs (%v)\n",
+		day, len(data), len(shrunk), strings.Count(shrunk, "\n"), stats.Tests, time.Since(start).Round(time.Millisecond))
+	if *output == "" {
+		fmt.Print(shrunk)
+		return nil
+	}
+	return os.WriteFile(*output, []byte(shrunk), 0o644)
+}
+
+type shrinkOutcome struct {
+	result runner.Result
+	err    error
+}
+
+func (o shrinkOutcome) timedOut() bool {
+	return errors.Is(o.err, runner.ErrTimeout)
+}
+
+// agrees reports whether both runs succeeded with the same answers or both
+// failed.
+func (o shrinkOutcome) agrees(other shrinkOutcome) bool {
+	if o.err != nil || other.err != nil {
+		return o.err != nil && other.err != nil
+	}
+	return slices.EqualFunc(o.result.Parts, other.result.Parts, func(a, b runner.Part) bool {
+		return a.Name == b.Name && a.Answer == b.Answer
+	})
+}
+
+var failureValueRe = regexp.MustCompile(`"[^"]*"|0x[0-9a-f]+|\d+`)
+
+// failure describes how a run failed, "" if it didn't: its first line that is
+// not an answer, with the numbers and quoted strings masked since they depend
+// on the input. A timeout is a different failure from an error.
+func (o shrinkOutcome) failure() string {
+	switch {
+	case o.err == nil:
+		return ""
+	case o.timedOut():
+		return "timeout"
+	}
+	for _, line := range strings.Split(o.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "(Part ") {
+			return failureValueRe.ReplaceAllString(line, "_")
+		}
+	}
+	return "error"
+}
+
+func (o shrinkOutcome) String() string {
+	if o.err != nil {
+		return o.result.Output + o.err.Error()
+	}
+	return o.result.Output
+}
+
+// readDayInput reads the input the day would run on, see runner.Options.Input.
+func readDayInput(day runner.Day, input string) (string, error) {
+	if input == "" {
+		input = runner.DefaultInput
+	}
+	if !filepath.IsAbs(input) {
+		input = filepath.Join(day.Dir, input)
+	}
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+	return string(data), nil
+}
+
+// buildAtRef extracts the day and, if it existed then, the aoc module as of
+// ref into dir and builds the day there.
+func buildAtRef(ctx context.Context, root string, day runner.Day, ref, dir string) (string, error) {
+	dayPath, err := filepath.Rel(root, day.Dir)
+	if err != nil {
+		return "", err
+	}
+
+	paths := []string{filepath.ToSlash(dayPath)}
+	// refs older than the aoc module only have the day
+	if _, err := git(root, "cat-file", "-e", ref+":aoc"); err == nil {
+		paths = append(paths, "aoc")
+	}
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"archive", "--format=tar", ref}, paths...)...)
+	cmd.Dir = root
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("error running git: %w", err)
+	}
+
+	extractErr := extractTar(stdout, dir)
+	// let git finish writing if the extraction stopped early
+	io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		return "", fmt.Errorf("error reading %v at %s: %s", day, ref, strings.TrimSpace(stderr.String()))
+	}
+	if extractErr != nil {
+		return "", extractErr
+	}
+
+	old := day
+	old.Dir = filepath.Join(dir, dayPath)
+	binary := filepath.Join(dir, "day")
+	if err := runner.BuildTo(ctx, old, binary); err != nil {
+		return "", fmt.Errorf("%s: %w", ref, err)
+	}
+	return binary, nil
+}
+
+func extractTar(r io.Reader, dir string) error {
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading archive: %w", err)
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(header.Name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			data, err := io.ReadAll(archive)
+			if err != nil {
+				return fmt.Errorf("error reading archive: %w", err)
+			}
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				return err
+			}
+		}
+	}
+}
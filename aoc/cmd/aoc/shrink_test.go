@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"aoc/runner"
+)
+
+func TestFailure(t *testing.T) {
+	day := runner.Day{Year: 2024, Day: 1}
+	noAnswers := func(output string) shrinkOutcome {
+		return shrinkOutcome{
+			result: runner.Result{Output: output},
+			err:    fmt.Errorf("%v printed no answers: %s", day, output),
+		}
+	}
+	crashed := func(output string) shrinkOutcome {
+		return shrinkOutcome{
+			result: runner.Result{Output: output},
+			err:    fmt.Errorf("error running %v: %w", day, errors.New("exit status 2")),
+		}
+	}
+
+	parseError := noAnswers(`error reading input: line 3: strconv.Atoi: parsing "x": invalid syntax`)
+	tests := []struct {
+		name string
+		a, b shrinkOutcome
+		same bool
+	}{
+		{"same panic, other values",
+			crashed("(Part one) sum: 12\npanic: runtime error: index out of range [5] with length 3\n\ngoroutine 1 [running]:\n"),
+			crashed("(Part one) sum: 4\npanic: runtime error: index out of range [2] with length 1\n\ngoroutine 1 [running]:\n"),
+			true},
+		{"same parse error, other line",
+			parseError,
+			noAnswers(`error reading input: line 1: strconv.Atoi: parsing "": invalid syntax`),
+			true},
+		{"parse error instead of the panic",
+			crashed("panic: runtime error: index out of range [5] with length 3\n"),
+			parseError,
+			false},
+		{"timeout instead of an error",
+			parseError,
+			shrinkOutcome{err: fmt.Errorf("%v %w after 1s", day, runner.ErrTimeout)},
+			false},
+		{"success",
+			parseError,
+			shrinkOutcome{result: runner.Result{Output: "(Part one) sum: 1\n"}},
+			false},
+	}
+	for _, test := range tests {
+		a, b := test.a.failure(), test.b.failure()
+		if a == "" {
+			t.Errorf("%s: a failing run has no failure", test.name)
+		}
+		if same := a == b; same != test.same {
+			t.Errorf("%s: got failures %q and %q, want same %t", test.name, a, b, test.same)
+		}
+	}
+}
+
+// commitAll commits everything in the repository at root.
+func commitAll(t *testing.T, root, message string) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", message},
+	} {
+		if _, err := git(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildAtRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	day := runner.Day{Year: 1999, Day: 1, Dir: filepath.Join(root, "1999", "day", "1")}
+	writeFile(t, filepath.Join(day.Dir, "go.mod"), "module day_one\n\ngo 1.23\n")
+	writeFile(t, filepath.Join(day.Dir, "main.go"), "package main\n\nfunc main() { println(\"(Part one) answer: 1\") }\n")
+	if _, err := git(root, "init", "-q"); err != nil {
+		t.Fatal(err)
+	}
+	commitAll(t, root, "before the aoc module")
+	writeFile(t, filepath.Join(root, "aoc", "go.mod"), "module aoc\n\ngo 1.23\n")
+	commitAll(t, root, "add the aoc module")
+
+	for _, test := range []struct {
+		ref string
+		aoc bool
+	}{
+		{"HEAD~", false},
+		{"HEAD", true},
+	} {
+		dir := t.TempDir()
+		binary, err := buildAtRef(context.Background(), root, day, test.ref, dir)
+		if err != nil {
+			t.Fatalf("%s: %v", test.ref, err)
+		}
+		if _, err := os.Stat(binary); err != nil {
+			t.Errorf("%s: %v", test.ref, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "aoc", "go.mod")); (err == nil) != test.aoc {
+			t.Errorf("%s: got aoc module extracted %t, want %t", test.ref, err == nil, test.aoc)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// key is a single key press read from the terminal.
+type key string
+
+const (
+	keyUp     key = "up"
+	keyDown   key = "down"
+	keyLeft   key = "left"
+	keyRight  key = "right"
+	keyEnter  key = "enter"
+	keyEscape key = "escape"
+)
+
+// terminal drives a full-screen interface on /dev/tty using stty and ANSI
+// escape sequences.
+type terminal struct {
+	tty   *os.File
+	saved string
+	keys  chan key
+
+	reading sync.WaitGroup
+	stop    chan struct{}
+}
+
+func openTerminal() (*terminal, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error opening terminal: %w", err)
+	}
+
+	t := &terminal{tty: tty, keys: make(chan key, 16)}
+	t.saved, err = t.stty("-g")
+	if err != nil {
+		tty.Close()
+		return nil, fmt.Errorf("error reading terminal state: %w", err)
+	}
+
+	if err := t.enter(); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// enter switches to the alternate screen in cbreak mode and starts reading keys.
+func (t *terminal) enter() error {
+	if _, err := t.stty("-icanon", "-echo", "min", "1", "time", "0"); err != nil {
+		return fmt.Errorf("error setting up terminal: %w", err)
+	}
+	fmt.Fprint(t.tty, "\x1b[?1049h\x1b[?25l")
+
+	t.stop = make(chan struct{})
+	t.reading.Add(1)
+	go t.readKeys()
+	return nil
+}
+
+// leave stops reading keys and restores the terminal, e.g. to hand it over to
+// a pager.
+func (t *terminal) leave() {
+	close(t.stop)
+	// a deadline in the past interrupts the pending read
+	t.tty.SetReadDeadline(time.Now())
+	t.reading.Wait()
+	t.tty.SetReadDeadline(time.Time{})
+
+	fmt.Fprint(t.tty, "\x1b[?25h\x1b[?1049l")
+	t.stty(t.saved)
+}
+
+func (t *terminal) Close() {
+	t.leave()
+	t.tty.Close()
+}
+
+func (t *terminal) readKeys() {
+	defer t.reading.Done()
+
+	buffer := make([]byte, 16)
+	for {
+		n, err := t.tty.Read(buffer)
+		select {
+		case <-t.stop:
+			return
+		default:
+		}
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		// nobody may be reading keys any more once stop is closed
+		for _, k := range parseKeys(string(buffer[:n])) {
+			select {
+			case t.keys <- k:
+			case <-t.stop:
+				return
+			}
+		}
+	}
+}
+
+var escapeKeys = map[string]key{
+	"\x1bOA": keyUp,
+	"\x1bOB": keyDown,
+	"\x1bOC": keyRight,
+	"\x1bOD": keyLeft,
+}
+
+// csiKeys maps the final bytes of control sequences to keys, whatever their
+// parameters, so that the modified arrows of ESC [ 1 ; 5 C still move.
+var csiKeys = map[byte]key{'A': keyUp, 'B': keyDown, 'C': keyRight, 'D': keyLeft}
+
+// parseKeys splits terminal input into key presses, several keys may arrive
+// in a single read.
+func parseKeys(input string) []key {
+	keys := make([]key, 0, len(input))
+	for len(input) > 0 {
+		if strings.HasPrefix(input, "\x1b[") {
+			// parameter and intermediate bytes run up to the final byte, a
+			// sequence cut off by the end of the read is dropped whole
+			end := 2
+			for end < len(input) && input[end] >= 0x20 && input[end] <= 0x3f {
+				end++
+			}
+			if end < len(input) && input[end] >= 0x40 && input[end] <= 0x7e {
+				if k, ok := csiKeys[input[end]]; ok {
+					keys = append(keys, k)
+				}
+				end++
+			}
+			input = input[end:]
+			continue
+		}
+		if len(input) >= 3 {
+			if k, ok := escapeKeys[input[:3]]; ok {
+				keys = append(keys, k)
+				input = input[3:]
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(input)
+		input = input[size:]
+		switch r {
+		case '\r', '\n':
+			keys = append(keys, keyEnter)
+		case '\x1b':
+			keys = append(keys, keyEscape)
+		default:
+			keys = append(keys, key(r))
+		}
+	}
+	return keys
+}
+
+// size returns the terminal height and width.
+func (t *terminal) size() (rows, cols int) {
+	output, err := t.stty("size")
+	if err != nil {
+		return 24, 80
+	}
+	if _, err := fmt.Sscan(output, &rows, &cols); err != nil || rows == 0 || cols == 0 {
+		return 24, 80
+	}
+	return rows, cols
+}
+
+// draw replaces the screen with the given lines.
+func (t *terminal) draw(lines []string) {
+	var sb strings.Builder
+	sb.WriteString("\x1b[H\x1b[2J")
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(line)
+	}
+	fmt.Fprint(t.tty, sb.String())
+}
+
+// runInteractive hands the terminal to an interactive command.
+func (t *terminal) runInteractive(cmd *exec.Cmd) error {
+	t.leave()
+	defer t.enter()
+
+	tty, err := childTTY()
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	return cmd.Run()
+}
+
+func (t *terminal) stty(args ...string) (string, error) {
+	tty, err := childTTY()
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// childTTY opens a separate handle on the terminal for child processes.
+// Handing them t.tty would put it into blocking mode, after which read
+// deadlines no longer interrupt readKeys.
+func childTTY() (*os.File, error) {
+	return os.OpenFile("/dev/tty", os.O_RDWR, 0)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"aoc/trace"
+	"aoc/visual"
+)
+
+func TestParseKeys(t *testing.T) {
+	got := parseKeys("j\x1b[A\x1bOBq\r\x1bé")
+	want := []key{"j", keyUp, keyDown, "q", keyEnter, keyEscape, "é"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Home, End, Delete and the like are not keys here, their final bytes
+	// and parameters must not reach the commands as G, ~ or digits
+	tests := []struct {
+		input string
+		want  []key
+	}{
+		{"\x1b[H\x1b[F", []key{}},
+		{"\x1b[1~\x1b[4~\x1b[3~G", []key{"G"}},
+		{"\x1b[1;5C\x1b[1;2A", []key{keyRight, keyUp}},
+		{"\x1b[200~j", []key{"j"}},
+		{"k\x1b[1;5", []key{"k"}},
+	}
+	for _, test := range tests {
+		if got := parseKeys(test.input); !slices.Equal(got, test.want) {
+			t.Errorf("%q: got %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+// TestLeaveWhileKeysPending stops reading keys while nobody takes them, as
+// when the interface quits with keys still arriving.
+func TestLeaveWhileKeysPending(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	// the terminal is the read end of a pipe, whose reads take deadlines
+	term := &terminal{tty: r, keys: make(chan key)}
+	term.stop = make(chan struct{})
+	term.reading.Add(1)
+	go term.readKeys()
+	if _, err := w.Write([]byte("jk")); err != nil {
+		t.Fatal(err)
+	}
+	// after taking j, readKeys is sending k
+	if k := <-term.keys; k != "j" {
+		t.Fatalf("got key %q, want j", k)
+	}
+
+	left := make(chan struct{})
+	go func() {
+		term.Close()
+		close(left)
+	}()
+	select {
+	case <-left:
+	case <-time.After(5 * time.Second):
+		t.Fatal("leaving the terminal blocked on undelivered keys")
+	}
+}
+
+func TestStepKeys(t *testing.T) {
+	// ↓ and j go forward, ↑ and k back, as in pagers
+	steps := []struct {
+		key  key
+		want int
+	}{
+		{keyDown, 10}, {"j", 20}, {keyRight, 21}, {"k", 11}, {keyUp, 1}, {keyLeft, 0}, {keyUp, 0},
+		{"G", 29}, {"j", 29}, {"g", 0},
+	}
+
+	r := &replay{events: make([]trace.Event, 30)}
+	for _, step := range steps {
+		r.handleKey(step.key)
+		if r.index != step.want {
+			t.Errorf("replay, %q: got event %d, want %d", step.key, r.index, step.want)
+		}
+	}
+
+	tui := &tui{vis: &visual.Visualization{Frames: make([]visual.Frame, 30)}}
+	for _, step := range steps {
+		tui.handleKey(context.Background(), step.key)
+		if tui.visFrame != step.want {
+			t.Errorf("visualization, %q: got frame %d, want %d", step.key, tui.visFrame, step.want)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"aoc/config"
+	"aoc/runner"
+	"aoc/visual"
+)
+
+type dayStatus string
+
+const (
+	statusNotRun     dayStatus = "not run"
+	statusRunning    dayStatus = "running"
+	statusSolved     dayStatus = "solved"
+	statusFailing    dayStatus = "failing"
+	statusUnverified dayStatus = "unverified"
+)
+
+var statusColors = map[dayStatus]string{
+	statusRunning:    "\x1b[36m",
+	statusSolved:     "\x1b[32m",
+	statusFailing:    "\x1b[31m",
+	statusUnverified: "\x1b[33m",
+}
+
+type tuiEntry struct {
+	day     runner.Day
+	status  dayStatus
+	started time.Time
+	result  runner.Result
+	message string
+}
+
+type tui struct {
+	env  *environment
+	term *terminal
+
+	mu       sync.Mutex
+	entries  []*tuiEntry
+	selected int
+	notice   string
+	// vis is the visualization being shown, nil in the day list
+	vis      *visual.Visualization
+	visDay   runner.Day
+	visFrame int
+
+	queue   chan *tuiEntry
+	updates chan struct{}
+}
+
+func tuiCommand(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("tui", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: aoc tui [year]")
+		flags.PrintDefaults()
+	}
+	positional, _, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	days, err := runner.List(env.root)
+	if err != nil {
+		return err
+	}
+
+	t := &tui{
+		env:     env,
+		queue:   make(chan *tuiEntry, len(days)),
+		updates: make(chan struct{}, 1),
+	}
+	for _, day := range days {
+		if len(positional) > 0 && fmt.Sprint(day.Year) != positional[0] {
+			continue
+		}
+		t.entries = append(t.entries, &tuiEntry{day: day, status: statusNotRun})
+	}
+	if len(t.entries) == 0 {
+		return fmt.Errorf("no days found")
+	}
+
+	t.term, err = openTerminal()
+	if err != nil {
+		return err
+	}
+	defer t.term.Close()
+
+	go t.worker(ctx)
+	return t.loop(ctx)
+}
+
+func (t *tui) loop(ctx context.Context) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		t.draw()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.updates:
+		case <-ticker.C:
+		case k := <-t.term.keys:
+			if quit := t.handleKey(ctx, k); quit {
+				return nil
+			}
+		}
+	}
+}
+
+func (t *tui) handleKey(ctx context.Context, k key) (quit bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.vis != nil {
+		last := len(t.vis.Frames) - 1
+		switch k {
+		case "q", keyEscape:
+			t.vis = nil
+		case keyLeft, "h":
+			t.visFrame = max(t.visFrame-1, 0)
+		case keyRight, "l":
+			t.visFrame = min(t.visFrame+1, last)
+		case keyUp, "k":
+			t.visFrame = max(t.visFrame-10, 0)
+		case keyDown, "j":
+			t.visFrame = min(t.visFrame+10, last)
+		case "g":
+			t.visFrame = 0
+		case "G":
+			t.visFrame = last
+		}
+		return false
+	}
+
+	entry := t.entries[t.selected]
+	switch k {
+	case "q":
+		return true
+	case keyUp, "k":
+		t.selected = max(t.selected-1, 0)
+	case keyDown, "j":
+		t.selected = min(t.selected+1, len(t.entries)-1)
+	case keyEnter, "r":
+		t.enqueue(entry)
+	case "a":
+		for _, e := range t.entries {
+			t.enqueue(e)
+		}
+	case "v":
+		if !entry.day.Visualizable() {
+			t.notice = fmt.Sprintf("%v has no visualization", entry.day)
+			break
+		}
+		t.notice = fmt.Sprintf("visualizing %v...", entry.day)
+		go t.visualize(ctx, entry.day)
+	case "i":
+		t.mu.Unlock()
+		t.openInput(entry.day)
+		t.mu.Lock()
+	}
+	return false
+}
+
+// enqueue schedules a run of the entry unless one is already pending.
+func (t *tui) enqueue(entry *tuiEntry) {
+	if entry.status == statusRunning {
+		return
+	}
+	entry.status = statusRunning
+	entry.started = time.Time{}
+	entry.message = "queued"
+	t.queue <- entry
+}
+
+// worker runs queued days one at a time so timings don't interfere.
+func (t *tui) worker(ctx context.Context) {
+	for {
+		var entry *tuiEntry
+		select {
+		case <-ctx.Done():
+			return
+		case entry = <-t.queue:
+		}
+
+		t.mu.Lock()
+		entry.started = time.Now()
+		entry.message = ""
+		t.mu.Unlock()
+
+		result, err := runner.Run(ctx, entry.day, t.env.options(entry.day, "", nil, nil))
+		status, message := evaluate(t.env.config.Answers(entry.day.Year, entry.day.Day), result, err)
+
+		t.mu.Lock()
+		entry.result = result
+		entry.status = status
+		entry.message = message
+		t.mu.Unlock()
+		t.notify()
+	}
+}
+
+// evaluate compares a result with the verified answers of the day.
+func evaluate(answers map[string]string, result runner.Result, err error) (dayStatus, string) {
+	if err != nil {
+		return statusFailing, err.Error()
+	}
+	if len(answers) == 0 {
+		return statusUnverified, "no verified answers in " + config.FileName
+	}
+
+	for _, part := range result.Parts {
+		expected, ok := answers[part.Name]
+		if !ok {
+			return statusUnverified, fmt.Sprintf("no verified answer for part %s", part.Name)
+		}
+		if part.Answer != expected {
+			return statusFailing, fmt.Sprintf("part %s: got %s, expected %s", part.Name, part.Answer, expected)
+		}
+	}
+	return statusSolved, ""
+}
+
+func (t *tui) visualize(ctx context.Context, day runner.Day) {
+	vis, err := func() (*visual.Visualization, error) {
+		binary, err := runner.Build(ctx, day)
+		if err != nil {
+			return nil, err
+		}
+		data, _, err := runner.Visualize(ctx, day, binary, t.env.options(day, "", nil, nil))
+		if err != nil {
+			return nil, err
+		}
+		var vis visual.Visualization
+		if err := json.Unmarshal(data, &vis); err != nil {
+			return nil, fmt.Errorf("error decoding visualization: %w", err)
+		}
+		return &vis, nil
+	}()
+
+	t.mu.Lock()
+	if err != nil {
+		t.notice = err.Error()
+	} else {
+		t.notice = ""
+		t.vis = vis
+		t.visDay = day
+		t.visFrame = 0
+		if vis.Cumulative {
+			t.visFrame = len(vis.Frames) - 1
+		}
+	}
+	t.mu.Unlock()
+	t.notify()
+}
+
+func (t *tui) openInput(day runner.Day) {
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less"}
+	}
+
+	cmd := exec.Command(pager[0], append(pager[1:], filepath.Join(day.Dir, runner.DefaultInput))...)
+	if err := t.term.runInteractive(cmd); err != nil {
+		t.mu.Lock()
+		t.notice = fmt.Sprintf("error running pager: %v", err)
+		t.mu.Unlock()
+	}
+}
+
+func (t *tui) notify() {
+	select {
+	case t.updates <- struct{}{}:
+	default:
+	}
+}
+
+func (t *tui) draw() {
+	rows, cols := t.term.size()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var lines []string
+	if t.vis != nil {
+		lines = t.visualizationLines(rows, cols)
+	} else {
+		lines = t.listLines(rows, cols)
+	}
+	t.term.draw(lines)
+}
+
+func (t *tui) listLines(rows, cols int) []string {
+	lines := []string{"\x1b[1maoc\x1b[0m", ""}
+
+	// keep the selection visible when there are more days than rows
+	visible := max(rows-6, 1)
+	first := max(0, min(t.selected-visible/2, len(t.entries)-visible))
+	for i := first; i < len(t.entries) && i < first+visible; i++ {
+		entry := t.entries[i]
+		cursor := "  "
+		if i == t.selected {
+			cursor = "\x1b[7m>\x1b[0m "
+		}
+
+		timing := ""
+		switch {
+		case entry.status == statusRunning && !entry.started.IsZero():
+			timing = time.Since(entry.started).Round(100 * time.Millisecond).String()
+		case entry.status != statusRunning && entry.result.Duration > 0:
+			timing = entry.result.Duration.Round(time.Microsecond).String()
+		}
+
+		answers := make([]string, 0, len(entry.result.Parts))
+		for _, part := range entry.result.Parts {
+			answers = append(answers, fmt.Sprintf("%s: %s", part.Name, part.Answer))
+		}
+
+		line := fmt.Sprintf("%-8v %s%-10s\x1b[0m %10s  %s",
+			entry.day, statusColors[entry.status], entry.status, timing, strings.Join(answers, "  "))
+		lines = append(lines, cursor+truncate(line, cols-2))
+	}
+
+	lines = append(lines, "")
+	message := t.notice
+	if message == "" {
+		message = t.entries[t.selected].message
+	}
+	lines = append(lines, truncate(firstLine(message), cols))
+	lines = append(lines, "\x1b[2m↑/↓ select  enter run  a run all  v visualize  i input  q quit\x1b[0m")
+
+	return lines
+}
+
+// visualizationLines renders the current frame with half blocks, two grid rows
+// per terminal line, cropped to the terminal.
+func (t *tui) visualizationLines(rows, cols int) []string {
+	frame := min(t.visFrame, len(t.vis.Frames)-1)
+	label := ""
+	if frame >= 0 {
+		label = t.vis.Frames[frame].Label
+	}
+	lines := []string{truncate(fmt.Sprintf("%v %s · %d/%d · %s", t.visDay, t.vis.Title, frame+1, len(t.vis.Frames), label), cols)}
+
+	colors := t.vis.Colors(frame)
+	for y := 0; y < len(colors) && len(lines) < rows-1; y += 2 {
+		var sb strings.Builder
+		for x := 0; x < len(colors[y]) && x < cols; x++ {
+			top := colors[y][x]
+			bottom := "#000"
+			if y+1 < len(colors) {
+				bottom = colors[y+1][x]
+			}
+			tr, tg, tb, _ := visual.RGB(top)
+			br, bg, bb, _ := visual.RGB(bottom)
+			fmt.Fprintf(&sb, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", tr, tg, tb, br, bg, bb)
+		}
+		sb.WriteString("\x1b[0m")
+		lines = append(lines, sb.String())
+	}
+
+	lines = append(lines, "\x1b[2m←/→ step  ↑/↓ step 10  g/G first/last  q back\x1b[0m")
+	return lines
+}
+
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	// escape sequences don't take up space, count only printed runes
+	var sb strings.Builder
+	printed := 0
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case r == '\x1b':
+			inEscape = true
+		case inEscape:
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+				inEscape = false
+			}
+		default:
+			if printed == width {
+				return sb.String() + "\x1b[0m"
+			}
+			printed++
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
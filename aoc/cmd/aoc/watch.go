@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"aoc/config"
+	"aoc/runner"
+)
+
+func watchCommand(ctx context.Context, env *environment, args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := flags.Duration("interval", 500*time.Millisecond, "how often to poll for changes")
+	var params multiFlag
+	flags.Var(&params, "param", "set a day parameter as `key=value` (repeatable)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: aoc watch [flags] [year] day [-- day flags]")
+		flags.PrintDefaults()
+	}
+
+	positional, passthrough, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	day, err := env.day(positional)
+	if err != nil {
+		return err
+	}
+
+	w := watcher{
+		env:         env,
+		day:         day,
+		params:      params,
+		passthrough: passthrough,
+		previous:    make(map[string]string),
+	}
+
+	fmt.Printf("watching %v, press Ctrl-C to stop\n", day)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	var lastSnapshot map[string]fileState
+	for {
+		snapshot, err := w.snapshot()
+		if err != nil {
+			return err
+		}
+		if !maps.Equal(snapshot, lastSnapshot) {
+			lastSnapshot = snapshot
+			w.runAll(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+type watcher struct {
+	env         *environment
+	day         runner.Day
+	params      []string
+	passthrough []string
+	// previous holds the last output of the tests and per input file
+	previous map[string]string
+}
+
+// snapshot records the state of every file a run depends on: the day's
+// sources and inputs as well as the shared aoc packages it may import.
+func (w *watcher) snapshot() (map[string]fileState, error) {
+	states := make(map[string]fileState)
+	record := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, ".txt") && filepath.Base(path) != "go.mod" {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		states[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	}
+
+	for _, dir := range []string{w.day.Dir, filepath.Join(w.env.root, "aoc")} {
+		if err := filepath.WalkDir(dir, record); err != nil {
+			return nil, fmt.Errorf("error scanning %s: %w", dir, err)
+		}
+	}
+
+	return states, nil
+}
+
+// inputs returns the example inputs of the day followed by its real input.
+func (w *watcher) inputs() []string {
+	examples, _ := filepath.Glob(filepath.Join(w.day.Dir, "example*.txt"))
+	slices.Sort(examples)
+
+	// full paths, as options would otherwise look for bare names in the
+	// directory the command runs from
+	return append(examples, filepath.Join(w.day.Dir, runner.DefaultInput))
+}
+
+func (w *watcher) runAll(ctx context.Context) {
+	fmt.Printf("\n=== %v at %s\n", w.day, time.Now().Format(time.TimeOnly))
+
+	// only failures are shown, a passing run differs by its timing alone
+	start := time.Now()
+	output, err := runner.Test(ctx, w.day)
+	verdict := "passing"
+	switch {
+	case err != nil:
+		verdict = string(statusFailing)
+		output += err.Error() + "\n"
+	case strings.Contains(output, "[no test files]"):
+		verdict, output = "no tests", ""
+	default:
+		output = ""
+	}
+	w.report("go test", time.Since(start), verdict, output)
+
+	binary, err := runner.Build(ctx, w.day)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, path := range w.inputs() {
+		input := filepath.Base(path)
+		result, err := runner.Exec(ctx, w.day, binary, w.env.options(w.day, path, w.params, w.passthrough))
+		output := result.Output
+		if err != nil {
+			output += err.Error() + "\n"
+		}
+
+		expected := w.env.config.ExpectedAnswers(w.day.Year, w.day.Day, input)
+		verdict := "no expected answers in " + config.FileName
+		switch {
+		case err != nil:
+			verdict = string(statusFailing)
+		case expected != nil:
+			status, message := evaluate(expected, result, nil)
+			verdict = string(status)
+			if message != "" {
+				verdict += ", " + message
+			}
+		}
+
+		w.report(input, result.Duration, verdict, output)
+	}
+}
+
+// report prints the verdict of a step and its output, or only what changed
+// since the previous run.
+func (w *watcher) report(name string, duration time.Duration, verdict, output string) {
+	fmt.Printf("--- %s (%v): %s\n", name, duration.Round(time.Microsecond), verdict)
+	previous, ok := w.previous[name]
+	switch {
+	case !ok:
+		fmt.Print(output)
+	case previous == output:
+		fmt.Println("  (unchanged)")
+	default:
+		fmt.Print(diffLines(previous, output, 2))
+	}
+	w.previous[name] = output
+}
@@ -0,0 +1,172 @@
+// Package config loads the runner configuration file, aoc.json in the
+// repository root, holding runner defaults and per-day parameters.
+//
+//	{
+//	  "year": 2024,
+//	  "timeout": "1m",
+//	  "url": "https://adventofcode.com",
+//	  "days": {
+//	    "2024/14": {
+//	      "answers": {"one": "228457125", "two": "6493"},
+//	      "params": {"seconds": 100},
+//	      "inputs": {
+//	        "example.txt": {"width": 11, "height": 7}
+//	      },
+//	      "examples": {
+//	        "example.txt": {"one": "12"}
+//	      }
+//	    }
+//	  }
+//	}
+//
+// Parameters listed under an input file only apply when the day runs with that
+// input and take precedence over the day's general parameters. Answers are the
+// verified answers for the day's own input.txt, examples the expected answers
+// for its example inputs, which aoc watch checks.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FileName is the name of the configuration file in the repository root.
+const FileName = "aoc.json"
+
+type Config struct {
+	// Year is used when a command is given a day without a year.
+	Year int `json:"year"`
+	// Timeout limits a single run of a day.
+	Timeout Duration `json:"timeout"`
+	// URL is the Advent of Code website that aoc fetch downloads from, which
+	// can be pointed at a local stub.
+	URL string `json:"url"`
+	// Days maps "year/day" to the day's configuration.
+	Days map[string]Day `json:"days"`
+}
+
+type Day struct {
+	Answers map[string]string           `json:"answers"`
+	Params  map[string]Value            `json:"params"`
+	Inputs  map[string]map[string]Value `json:"inputs"`
+	// Examples maps the name of an example input to its expected answers.
+	Examples map[string]map[string]string `json:"examples"`
+}
+
+// Default returns the configuration used when there is no file.
+func Default() Config {
+	return Config{
+		Year:    2024,
+		Timeout: Duration(5 * time.Minute),
+		URL:     "https://adventofcode.com",
+		Days:    map[string]Day{},
+	}
+}
+
+// Load reads the configuration from path. A missing file yields Default.
+func Load(path string) (Config, error) {
+	config := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("error reading config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("error parsing config %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// Params returns the parameters for running year/day with the given input as
+// sorted key=value assignments, input-specific values overriding general ones.
+func (c Config) Params(year, day int, input string) []string {
+	dayConfig := c.Days[fmt.Sprintf("%d/%d", year, day)]
+
+	merged := make(map[string]Value)
+	for key, value := range dayConfig.Params {
+		merged[key] = value
+	}
+	if input != "" {
+		for key, value := range dayConfig.Inputs[filepath.Base(input)] {
+			merged[key] = value
+		}
+	}
+
+	params := make([]string, 0, len(merged))
+	for key, value := range merged {
+		params = append(params, key+"="+string(value))
+	}
+	slices.Sort(params)
+
+	return params
+}
+
+// Value is a parameter value, written in the file as a string, number or bool.
+type Value string
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*v = Value(s)
+		return nil
+	}
+
+	text := strings.TrimSpace(string(data))
+	if text == "null" || strings.ContainsAny(text[:1], "[{") {
+		return fmt.Errorf("parameter value must be a string, number or bool, got %s", text)
+	}
+	*v = Value(text)
+	return nil
+}
+
+// Duration is a time.Duration written in the file as a string like "30s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Answers returns the verified answers of year/day keyed by part name ("one",
+// "two"), or nil if none are known.
+func (c Config) Answers(year, day int) map[string]string {
+	return c.Days[fmt.Sprintf("%d/%d", year, day)].Answers
+}
+
+// ExpectedAnswers returns the answers expected when year/day runs with input:
+// the verified answers for input.txt or an empty input, the answers of the
+// example otherwise, nil if none are known.
+func (c Config) ExpectedAnswers(year, day int, input string) map[string]string {
+	if input == "" || filepath.Base(input) == "input.txt" {
+		return c.Answers(year, day)
+	}
+	return c.Days[fmt.Sprintf("%d/%d", year, day)].Examples[filepath.Base(input)]
+}
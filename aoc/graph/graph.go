@@ -0,0 +1,109 @@
+// Package graph provides adjacency-list graphs over any comparable vertex type.
+//
+// Vertices and edges are kept in insertion order, so every traversal is
+// deterministic for a given sequence of AddVertex/AddEdge calls.
+package graph
+
+import "slices"
+
+// Edge is an outgoing edge of a vertex.
+type Edge[V comparable] struct {
+	To     V
+	Weight int
+}
+
+// Graph is a directed graph. Undirected graphs are built with AddUndirectedEdge,
+// which stores one edge in each direction.
+type Graph[V comparable] struct {
+	vertices []V
+	edges    map[V][]Edge[V]
+}
+
+func New[V comparable]() *Graph[V] {
+	return &Graph[V]{edges: make(map[V][]Edge[V])}
+}
+
+func (g *Graph[V]) AddVertex(v V) {
+	if g.HasVertex(v) {
+		return
+	}
+	g.vertices = append(g.vertices, v)
+	g.edges[v] = nil
+}
+
+// AddEdge adds a directed edge with weight 1.
+func (g *Graph[V]) AddEdge(from, to V) {
+	g.AddWeightedEdge(from, to, 1)
+}
+
+func (g *Graph[V]) AddWeightedEdge(from, to V, weight int) {
+	g.AddVertex(from)
+	g.AddVertex(to)
+	g.edges[from] = append(g.edges[from], Edge[V]{To: to, Weight: weight})
+}
+
+func (g *Graph[V]) AddUndirectedEdge(a, b V) {
+	g.AddEdge(a, b)
+	g.AddEdge(b, a)
+}
+
+func (g *Graph[V]) HasVertex(v V) bool {
+	_, ok := g.edges[v]
+	return ok
+}
+
+func (g *Graph[V]) HasEdge(from, to V) bool {
+	return slices.ContainsFunc(g.edges[from], func(e Edge[V]) bool {
+		return e.To == to
+	})
+}
+
+func (g *Graph[V]) Len() int {
+	return len(g.vertices)
+}
+
+// Vertices returns a copy of all vertices in insertion order.
+func (g *Graph[V]) Vertices() []V {
+	return slices.Clone(g.vertices)
+}
+
+// Edges returns the outgoing edges of v. The slice must not be modified.
+func (g *Graph[V]) Edges(v V) []Edge[V] {
+	return g.edges[v]
+}
+
+func (g *Graph[V]) Neighbors(v V) []V {
+	neighbors := make([]V, len(g.edges[v]))
+	for i, e := range g.edges[v] {
+		neighbors[i] = e.To
+	}
+	return neighbors
+}
+
+// Degree returns the number of outgoing edges of v.
+func (g *Graph[V]) Degree(v V) int {
+	return len(g.edges[v])
+}
+
+// Subgraph returns the graph induced by keep: its vertices in the given order
+// and every edge of g whose both ends are in keep.
+func (g *Graph[V]) Subgraph(keep []V) *Graph[V] {
+	inSubgraph := make(map[V]bool, len(keep))
+	for _, v := range keep {
+		inSubgraph[v] = true
+	}
+
+	sub := New[V]()
+	for _, v := range keep {
+		sub.AddVertex(v)
+	}
+	for _, v := range keep {
+		for _, e := range g.edges[v] {
+			if inSubgraph[e.To] {
+				sub.AddWeightedEdge(v, e.To, e.Weight)
+			}
+		}
+	}
+
+	return sub
+}
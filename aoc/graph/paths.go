@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// Paths holds single-source shortest path results.
+type Paths[V comparable] struct {
+	start    V
+	distance map[V]int
+	previous map[V]V
+}
+
+// Distance returns the length of the shortest path from the start vertex to v
+// and whether v is reachable at all.
+func (p Paths[V]) Distance(v V) (int, bool) {
+	d, ok := p.distance[v]
+	return d, ok
+}
+
+// PathTo returns the vertices of a shortest path from the start vertex to v,
+// both ends included, or nil if v is unreachable.
+func (p Paths[V]) PathTo(v V) []V {
+	if _, ok := p.distance[v]; !ok {
+		return nil
+	}
+
+	path := []V{v}
+	for v != p.start {
+		v = p.previous[v]
+		path = append(path, v)
+	}
+	slices.Reverse(path)
+
+	return path
+}
+
+// ShortestPaths runs Dijkstra's algorithm from start. Edge weights must not be
+// negative.
+func (g *Graph[V]) ShortestPaths(start V) Paths[V] {
+	paths := Paths[V]{
+		start:    start,
+		distance: make(map[V]int),
+		previous: make(map[V]V),
+	}
+	if !g.HasVertex(start) {
+		return paths
+	}
+
+	paths.distance[start] = 0
+	queue := &priorityQueue[V]{{vertex: start}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(queueItem[V])
+		if current.distance > paths.distance[current.vertex] {
+			continue
+		}
+
+		for _, e := range g.edges[current.vertex] {
+			distance := current.distance + e.Weight
+			if known, ok := paths.distance[e.To]; ok && known <= distance {
+				continue
+			}
+			paths.distance[e.To] = distance
+			paths.previous[e.To] = current.vertex
+			heap.Push(queue, queueItem[V]{vertex: e.To, distance: distance})
+		}
+	}
+
+	return paths
+}
+
+// ShortestPath returns a shortest path from one vertex to another and its
+// length, or false if to is unreachable.
+func (g *Graph[V]) ShortestPath(from, to V) ([]V, int, bool) {
+	paths := g.ShortestPaths(from)
+	distance, ok := paths.Distance(to)
+	if !ok {
+		return nil, 0, false
+	}
+	return paths.PathTo(to), distance, true
+}
+
+type queueItem[V comparable] struct {
+	vertex   V
+	distance int
+}
+
+type priorityQueue[V comparable] []queueItem[V]
+
+func (q priorityQueue[V]) Len() int           { return len(q) }
+func (q priorityQueue[V]) Less(i, j int) bool { return q[i].distance < q[j].distance }
+func (q priorityQueue[V]) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *priorityQueue[V]) Push(x any) {
+	*q = append(*q, x.(queueItem[V]))
+}
+
+func (q *priorityQueue[V]) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
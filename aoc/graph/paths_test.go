@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestShortestPath(t *testing.T) {
+	g := New[string]()
+	g.AddWeightedEdge("a", "b", 7)
+	g.AddWeightedEdge("a", "c", 2)
+	g.AddWeightedEdge("c", "b", 3)
+	g.AddWeightedEdge("b", "d", 1)
+	g.AddWeightedEdge("c", "d", 9)
+	g.AddWeightedEdge("e", "a", 1)
+
+	path, distance, ok := g.ShortestPath("a", "d")
+	if !ok || distance != 6 || !slices.Equal(path, []string{"a", "c", "b", "d"}) {
+		t.Errorf("a to d: got %v, %d, %t, want [a c b d], 6", path, distance, ok)
+	}
+	if path, distance, ok := g.ShortestPath("a", "a"); !ok || distance != 0 || !slices.Equal(path, []string{"a"}) {
+		t.Errorf("a to a: got %v, %d, %t, want [a], 0", path, distance, ok)
+	}
+
+	// e leads to a, not the other way round
+	paths := g.ShortestPaths("a")
+	if distance, ok := paths.Distance("e"); ok {
+		t.Errorf("unreachable e: got distance %d", distance)
+	}
+	if path := paths.PathTo("e"); path != nil {
+		t.Errorf("unreachable e: got path %v", path)
+	}
+	if _, _, ok := g.ShortestPath("a", "missing"); ok {
+		t.Error("missing vertex: got a path")
+	}
+	if _, ok := g.ShortestPaths("missing").Distance("missing"); ok {
+		t.Error("missing start: got a distance to itself")
+	}
+}
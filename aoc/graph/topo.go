@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+)
+
+// CycleError reports a cycle found while ordering a graph. Cycle starts and
+// ends with the same vertex.
+type CycleError[V comparable] struct {
+	Cycle []V
+}
+
+func (e *CycleError[V]) Error() string {
+	return fmt.Sprintf("graph contains a cycle: %v", e.Cycle)
+}
+
+// TopologicalSort orders all vertices so that every edge points forward.
+// It returns a *CycleError if no such order exists.
+func (g *Graph[V]) TopologicalSort() ([]V, error) {
+	return g.topologicalOrder(g.vertices)
+}
+
+type visitState int
+
+const (
+	unvisited visitState = iota
+	inProgress
+	done
+)
+
+// topologicalOrder orders the vertices reachable from starts using an
+// iterative depth-first search, reporting the first back edge as a cycle.
+func (g *Graph[V]) topologicalOrder(starts []V) ([]V, error) {
+	type frame struct {
+		vertex V
+		next   int
+	}
+
+	state := make(map[V]visitState, len(g.vertices))
+	order := make([]V, 0, len(g.vertices))
+	for _, start := range starts {
+		if state[start] != unvisited {
+			continue
+		}
+
+		state[start] = inProgress
+		stack := []frame{{vertex: start}}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			edges := g.edges[top.vertex]
+			if top.next == len(edges) {
+				state[top.vertex] = done
+				order = append(order, top.vertex)
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			to := edges[top.next].To
+			top.next++
+			switch state[to] {
+			case unvisited:
+				state[to] = inProgress
+				stack = append(stack, frame{vertex: to})
+			case inProgress:
+				i := slices.IndexFunc(stack, func(f frame) bool { return f.vertex == to })
+				cycle := make([]V, 0, len(stack)-i+1)
+				for _, f := range stack[i:] {
+					cycle = append(cycle, f.vertex)
+				}
+				cycle = append(cycle, to)
+				return nil, &CycleError[V]{Cycle: cycle}
+			}
+		}
+	}
+
+	slices.Reverse(order)
+	return order, nil
+}
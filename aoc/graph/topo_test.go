@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTopologicalSort(t *testing.T) {
+	g := New[string]()
+	g.AddEdge("shirt", "tie")
+	g.AddEdge("tie", "jacket")
+	g.AddEdge("trousers", "shoes")
+	g.AddEdge("trousers", "belt")
+	g.AddEdge("belt", "jacket")
+	g.AddEdge("socks", "shoes")
+	g.AddVertex("watch")
+
+	order, err := g.TopologicalSort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != g.Len() {
+		t.Fatalf("got %v, want all %d vertices", order, g.Len())
+	}
+	for _, v := range g.Vertices() {
+		for _, to := range g.Neighbors(v) {
+			if slices.Index(order, v) > slices.Index(order, to) {
+				t.Errorf("%v: %s comes after %s", order, v, to)
+			}
+		}
+	}
+}
+
+func TestCycleError(t *testing.T) {
+	g := New[int]()
+	g.AddEdge(1, 2)
+	g.AddEdge(2, 3)
+	g.AddEdge(3, 4)
+	g.AddEdge(4, 2)
+
+	_, err := g.TopologicalSort()
+	var cycleErr *CycleError[int]
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("got %v, want a *CycleError", err)
+	}
+	if want := []int{2, 3, 4, 2}; !slices.Equal(cycleErr.Cycle, want) {
+		t.Errorf("got cycle %v, want %v", cycleErr.Cycle, want)
+	}
+	if got, want := err.Error(), "graph contains a cycle: [2 3 4 2]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// a self loop is a cycle too
+	g = New[int]()
+	g.AddEdge(1, 1)
+	if _, err := g.TopologicalSort(); !errors.As(err, &cycleErr) || !slices.Equal(cycleErr.Cycle, []int{1, 1}) {
+		t.Errorf("self loop: got %v, want cycle [1 1]", err)
+	}
+}
@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"iter"
+	"slices"
+)
+
+// BFS yields the vertices reachable from start in breadth-first order,
+// start included.
+func (g *Graph[V]) BFS(start V) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		if !g.HasVertex(start) {
+			return
+		}
+
+		visited := map[V]bool{start: true}
+		queue := []V{start}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			if !yield(current) {
+				return
+			}
+
+			for _, e := range g.edges[current] {
+				if !visited[e.To] {
+					visited[e.To] = true
+					queue = append(queue, e.To)
+				}
+			}
+		}
+	}
+}
+
+// DFS yields the vertices reachable from start in depth-first pre-order,
+// visiting neighbors in insertion order.
+func (g *Graph[V]) DFS(start V) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		if !g.HasVertex(start) {
+			return
+		}
+
+		visited := make(map[V]bool)
+		stack := []V{start}
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if visited[current] {
+				continue
+			}
+			visited[current] = true
+			if !yield(current) {
+				return
+			}
+
+			// pushed in reverse so the first neighbor is popped first
+			for _, e := range slices.Backward(g.edges[current]) {
+				if !visited[e.To] {
+					stack = append(stack, e.To)
+				}
+			}
+		}
+	}
+}
+
+// Reachable returns all vertices reachable from start, start included.
+func (g *Graph[V]) Reachable(start V) []V {
+	return slices.Collect(g.BFS(start))
+}
+
+// Components returns the weakly connected components of the graph, i.e. edge
+// direction is ignored. Components are ordered by their first vertex and keep
+// insertion order within.
+func (g *Graph[V]) Components() [][]V {
+	parent := make(map[V]V, len(g.vertices))
+	var find func(v V) V
+	find = func(v V) V {
+		root := v
+		for parent[root] != root {
+			root = parent[root]
+		}
+		for parent[v] != root {
+			parent[v], v = root, parent[v]
+		}
+		return root
+	}
+
+	for _, v := range g.vertices {
+		parent[v] = v
+	}
+	for _, v := range g.vertices {
+		for _, e := range g.edges[v] {
+			a, b := find(v), find(e.To)
+			if a != b {
+				parent[b] = a
+			}
+		}
+	}
+
+	index := make(map[V]int)
+	components := make([][]V, 0)
+	for _, v := range g.vertices {
+		root := find(v)
+		i, ok := index[root]
+		if !ok {
+			i = len(components)
+			index[root] = i
+			components = append(components, nil)
+		}
+		components[i] = append(components[i], v)
+	}
+
+	return components
+}
+
+// CountPaths returns, for every vertex reachable from start, the number of
+// distinct paths leading to it from start. It fails with a *CycleError when a
+// cycle is reachable, since the count would be infinite.
+func (g *Graph[V]) CountPaths(start V) (map[V]int, error) {
+	if !g.HasVertex(start) {
+		return map[V]int{}, nil
+	}
+
+	order, err := g.topologicalOrder([]V{start})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[V]int{start: 1}
+	for _, v := range order {
+		for _, e := range g.edges[v] {
+			counts[e.To] += counts[v]
+		}
+	}
+
+	return counts, nil
+}
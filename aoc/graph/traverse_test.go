@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+// newTree returns 1 -> 2, 3; 2 -> 4, 5; 3 -> 6, plus 7 -> 1 and a separate
+// 8 -> 9, so 7, 8 and 9 are unreachable from 1.
+func newTree() *Graph[int] {
+	g := New[int]()
+	for _, edge := range [][2]int{{1, 2}, {1, 3}, {2, 4}, {2, 5}, {3, 6}, {7, 1}, {8, 9}} {
+		g.AddEdge(edge[0], edge[1])
+	}
+	return g
+}
+
+func TestTraversals(t *testing.T) {
+	g := newTree()
+	if got, want := slices.Collect(g.BFS(1)), []int{1, 2, 3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("BFS: got %v, want %v", got, want)
+	}
+	if got, want := slices.Collect(g.DFS(1)), []int{1, 2, 4, 5, 3, 6}; !slices.Equal(got, want) {
+		t.Errorf("DFS: got %v, want %v", got, want)
+	}
+	if got, want := g.Reachable(3), []int{3, 6}; !slices.Equal(got, want) {
+		t.Errorf("Reachable(3): got %v, want %v", got, want)
+	}
+	if got := g.Reachable(10); len(got) != 0 {
+		t.Errorf("Reachable of a missing vertex: got %v", got)
+	}
+
+	// stopping early
+	var first []int
+	for v := range g.BFS(1) {
+		first = append(first, v)
+		if len(first) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(first, []int{1, 2}) {
+		t.Errorf("BFS stopped after 2: got %v", first)
+	}
+}
+
+func TestComponents(t *testing.T) {
+	g := newTree()
+	g.AddVertex(10)
+	want := [][]int{{1, 2, 3, 4, 5, 6, 7}, {8, 9}, {10}}
+	if got := g.Components(); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCountPaths(t *testing.T) {
+	// a chain of three diamonds, 2^3 paths from end to end
+	g := New[int]()
+	for i := 0; i < 9; i += 3 {
+		g.AddEdge(i, i+1)
+		g.AddEdge(i, i+2)
+		g.AddEdge(i+1, i+3)
+		g.AddEdge(i+2, i+3)
+	}
+	// unreachable from 0, and so not counted
+	g.AddEdge(20, 3)
+
+	counts, err := g.CountPaths(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]int{0: 1, 1: 1, 2: 1, 3: 2, 4: 2, 5: 2, 6: 4, 7: 4, 8: 4, 9: 8}
+	if !maps.Equal(counts, want) {
+		t.Errorf("got %v, want %v", counts, want)
+	}
+
+	if counts, err := g.CountPaths(99); err != nil || len(counts) != 0 {
+		t.Errorf("missing start: got %v, %v", counts, err)
+	}
+
+	// a cycle only matters where it is reachable
+	g.AddEdge(30, 31)
+	g.AddEdge(31, 30)
+	if _, err := g.CountPaths(0); err != nil {
+		t.Errorf("unreachable cycle: got %v", err)
+	}
+	g.AddEdge(9, 30)
+	var cycleErr *CycleError[int]
+	if _, err := g.CountPaths(0); !errors.As(err, &cycleErr) {
+		t.Errorf("reachable cycle: got %v, want a *CycleError", err)
+	}
+}
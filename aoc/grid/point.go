@@ -0,0 +1,145 @@
+// Package grid provides points and directions on the 2D grids of the
+// puzzles. X grows to the right and Y grows down, so that a point indexes a
+// grid as rows[p.Y][p.X] and Up is towards the first row.
+package grid
+
+import (
+	"fmt"
+
+	"aoc/intmath"
+)
+
+// Point is a position or a displacement on a grid.
+type Point struct {
+	X, Y int
+}
+
+func (p Point) Add(q Point) Point {
+	return Point{X: p.X + q.X, Y: p.Y + q.Y}
+}
+
+func (p Point) Sub(q Point) Point {
+	return Point{X: p.X - q.X, Y: p.Y - q.Y}
+}
+
+func (p Point) Scale(k int) Point {
+	return Point{X: p.X * k, Y: p.Y * k}
+}
+
+func (p Point) Neg() Point {
+	return Point{X: -p.X, Y: -p.Y}
+}
+
+// Manhattan returns the distance to q moving along the axes.
+func (p Point) Manhattan(q Point) int {
+	return intmath.Abs(p.X-q.X) + intmath.Abs(p.Y-q.Y)
+}
+
+// Chebyshev returns the distance to q moving along the axes and diagonals.
+func (p Point) Chebyshev(q Point) int {
+	return max(intmath.Abs(p.X-q.X), intmath.Abs(p.Y-q.Y))
+}
+
+// RotateRight rotates a displacement a quarter turn clockwise as seen on the
+// grid, turning Up into Right.
+func (p Point) RotateRight() Point {
+	return Point{X: -p.Y, Y: p.X}
+}
+
+// RotateLeft rotates a displacement a quarter turn counterclockwise as seen on
+// the grid, turning Up into Left.
+func (p Point) RotateLeft() Point {
+	return Point{X: p.Y, Y: -p.X}
+}
+
+// Wrap brings p back onto a width by height grid whose edges wrap around.
+func (p Point) Wrap(width, height int) Point {
+	return Point{X: intmath.Mod(p.X, width), Y: intmath.Mod(p.Y, height)}
+}
+
+// Move returns the adjacent point in direction d.
+func (p Point) Move(d Direction) Point {
+	return p.Add(d.Delta())
+}
+
+// Neighbors returns the four adjacent points, in the order of Directions.
+func (p Point) Neighbors() [4]Point {
+	var neighbors [4]Point
+	for i, d := range Directions {
+		neighbors[i] = p.Move(d)
+	}
+	return neighbors
+}
+
+// In reports whether p lies on a width by height grid.
+func (p Point) In(width, height int) bool {
+	return p.X >= 0 && p.X < width && p.Y >= 0 && p.Y < height
+}
+
+// Within reports whether p indexes a cell of rows, which may be ragged.
+func Within[T any](p Point, rows [][]T) bool {
+	return p.Y >= 0 && p.Y < len(rows) && p.X >= 0 && p.X < len(rows[p.Y])
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("%d,%d", p.X, p.Y)
+}
+
+// Direction is one of the four directions along the axes, in clockwise order.
+type Direction int
+
+const (
+	Up Direction = iota
+	Right
+	Down
+	Left
+)
+
+// Directions lists the directions in clockwise order starting with Up.
+var Directions = [4]Direction{Up, Right, Down, Left}
+
+var deltas = [4]Point{Up: {0, -1}, Right: {1, 0}, Down: {0, 1}, Left: {-1, 0}}
+
+// Delta returns the displacement of a single step in direction d.
+func (d Direction) Delta() Point {
+	return deltas[d]
+}
+
+func (d Direction) TurnRight() Direction {
+	return (d + 1) % 4
+}
+
+func (d Direction) TurnLeft() Direction {
+	return (d + 3) % 4
+}
+
+func (d Direction) Opposite() Direction {
+	return (d + 2) % 4
+}
+
+var arrows = [4]rune{Up: '^', Right: '>', Down: 'v', Left: '<'}
+
+// Rune returns the arrow for d as drawn in the puzzles: ^, >, v or <.
+func (d Direction) Rune() rune {
+	return arrows[d]
+}
+
+func (d Direction) String() string {
+	return [4]string{Up: "up", Right: "right", Down: "down", Left: "left"}[d]
+}
+
+// ParseDirection reads an arrow (^ > v <) or a compass letter (N E S W), north
+// being Up.
+func ParseDirection(r rune) (Direction, error) {
+	switch r {
+	case '^', 'N':
+		return Up, nil
+	case '>', 'E':
+		return Right, nil
+	case 'v', 'S':
+		return Down, nil
+	case '<', 'W':
+		return Left, nil
+	}
+	return 0, fmt.Errorf("invalid direction %q", r)
+}
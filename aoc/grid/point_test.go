@@ -0,0 +1,113 @@
+package grid
+
+import (
+	"testing"
+)
+
+func TestPointArithmetic(t *testing.T) {
+	p, q := Point{3, -2}, Point{-1, 5}
+	tests := []struct {
+		name      string
+		got, want Point
+	}{
+		{"Add", p.Add(q), Point{2, 3}},
+		{"Sub", p.Sub(q), Point{4, -7}},
+		{"Scale", p.Scale(-2), Point{-6, 4}},
+		{"Neg", p.Neg(), Point{-3, 2}},
+		{"Wrap", Point{-1, 7}.Wrap(11, 7), Point{10, 0}},
+		{"Wrap far", Point{-23, -15}.Wrap(11, 7), Point{10, 6}},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+	if got := p.Manhattan(q); got != 11 {
+		t.Errorf("Manhattan: got %d, want 11", got)
+	}
+	if got := p.Chebyshev(q); got != 7 {
+		t.Errorf("Chebyshev: got %d, want 7", got)
+	}
+	if got := p.String(); got != "3,-2" {
+		t.Errorf("String: got %q, want 3,-2", got)
+	}
+}
+
+func TestTurns(t *testing.T) {
+	for i, d := range Directions {
+		next := Directions[(i+1)%4]
+		if got := d.TurnRight(); got != next {
+			t.Errorf("%v.TurnRight() = %v, want %v", d, got, next)
+		}
+		if got := next.TurnLeft(); got != d {
+			t.Errorf("%v.TurnLeft() = %v, want %v", next, got, d)
+		}
+		if got := d.Opposite(); got.Delta() != d.Delta().Neg() {
+			t.Errorf("%v.Opposite() = %v", d, got)
+		}
+		// the displacements turn the same way as the directions
+		if got := d.Delta().RotateRight(); got != next.Delta() {
+			t.Errorf("%v.Delta().RotateRight() = %v, want %v", d, got, next.Delta())
+		}
+		if got := next.Delta().RotateLeft(); got != d.Delta() {
+			t.Errorf("%v.Delta().RotateLeft() = %v, want %v", next, got, d.Delta())
+		}
+	}
+	if Up.Delta() != (Point{0, -1}) || Right.Delta() != (Point{1, 0}) {
+		t.Errorf("up is %v and right %v, want 0,-1 and 1,0", Up.Delta(), Right.Delta())
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	p := Point{4, 7}
+	want := [4]Point{{4, 6}, {5, 7}, {4, 8}, {3, 7}}
+	if got := p.Neighbors(); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for i, d := range Directions {
+		if got := p.Move(d); got != want[i] {
+			t.Errorf("Move(%v): got %v, want %v", d, got, want[i])
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	rows := [][]byte{[]byte("abc"), []byte("d"), []byte("ef")}
+	tests := []struct {
+		p          Point
+		in, within bool
+	}{
+		{Point{0, 0}, true, true},
+		{Point{2, 0}, true, true},
+		{Point{2, 1}, true, false},
+		{Point{1, 2}, true, true},
+		{Point{3, 0}, false, false},
+		{Point{0, 3}, false, false},
+		{Point{-1, 0}, false, false},
+		{Point{0, -1}, false, false},
+	}
+	for _, test := range tests {
+		if got := test.p.In(3, 3); got != test.in {
+			t.Errorf("%v.In(3, 3) = %t, want %t", test.p, got, test.in)
+		}
+		if got := Within(test.p, rows); got != test.within {
+			t.Errorf("Within(%v) = %t, want %t", test.p, got, test.within)
+		}
+	}
+}
+
+func TestParseDirection(t *testing.T) {
+	for _, d := range Directions {
+		if got, err := ParseDirection(d.Rune()); err != nil || got != d {
+			t.Errorf("ParseDirection(%q) = %v, %v, want %v", d.Rune(), got, err, d)
+		}
+	}
+	for r, want := range map[rune]Direction{'N': Up, 'E': Right, 'S': Down, 'W': Left} {
+		if got, err := ParseDirection(r); err != nil || got != want {
+			t.Errorf("ParseDirection(%q) = %v, %v, want %v", r, got, err, want)
+		}
+	}
+	if _, err := ParseDirection('x'); err == nil {
+		t.Error("ParseDirection('x'): got no error")
+	}
+}
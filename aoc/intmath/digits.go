@@ -0,0 +1,80 @@
+package intmath
+
+// Pow10 returns 10^n for n >= 0. It wraps past 10^18, see CheckedPow10.
+func Pow10(n int) int {
+	result := 1
+	for range n {
+		result *= 10
+	}
+	return result
+}
+
+// CheckedPow10 returns 10^n for n >= 0 and false if it does not fit in an int.
+func CheckedPow10(n int) (int, bool) {
+	result := 1
+	for range n {
+		var ok bool
+		if result, ok = CheckedMul(result, 10); !ok {
+			return 0, false
+		}
+	}
+	return result, true
+}
+
+// DigitCount returns the number of decimal digits of n, ignoring the sign.
+// Zero has one digit.
+func DigitCount(n int) int {
+	// count on the negative side, which unlike the positive one holds
+	// math.MinInt
+	if n > 0 {
+		n = -n
+	}
+	count := 1
+	for n <= -10 {
+		n /= 10
+		count++
+	}
+	return count
+}
+
+// SplitDigits splits the decimal representation of a non-negative n before
+// its last k digits, so SplitDigits(123456, 2) is (1234, 56).
+func SplitDigits(n, k int) (left, right int) {
+	divisor := Pow10(k)
+	return n / divisor, n % divisor
+}
+
+// Halve splits a number with an even digit count into its left and right
+// halves. The right half drops leading zeros: Halve(1000) is (10, 0).
+func Halve(n int) (left, right int, ok bool) {
+	digits := DigitCount(n)
+	if digits%2 != 0 {
+		return 0, 0, false
+	}
+	left, right = SplitDigits(n, digits/2)
+	return left, right, true
+}
+
+// Concat appends the decimal digits of non-negative b to a, so Concat(12, 345)
+// is 12345. It wraps if the result does not fit in an int, see CheckedConcat.
+func Concat(a, b int) int {
+	return a*Pow10(DigitCount(b)) + b
+}
+
+// CheckedConcat is Concat reporting false if the result does not fit in an
+// int.
+func CheckedConcat(a, b int) (int, bool) {
+	if a == 0 {
+		// even when the shift alone would overflow
+		return b, true
+	}
+	shift, ok := CheckedPow10(DigitCount(b))
+	if !ok {
+		return 0, false
+	}
+	shifted, ok := CheckedMul(a, shift)
+	if !ok {
+		return 0, false
+	}
+	return CheckedAdd(shifted, b)
+}
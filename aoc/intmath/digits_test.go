@@ -0,0 +1,49 @@
+package intmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDigitCount(t *testing.T) {
+	tests := []struct{ n, want int }{
+		{0, 1}, {9, 1}, {10, 2}, {-10, 2}, {-9, 1}, {123456, 6},
+		{math.MaxInt, 19}, {math.MinInt, 19}, {math.MinInt + 1, 19},
+	}
+	for _, test := range tests {
+		if got := DigitCount(test.n); got != test.want {
+			t.Errorf("DigitCount(%d) = %d, want %d", test.n, got, test.want)
+		}
+	}
+}
+
+func TestCheckedConcat(t *testing.T) {
+	tests := []struct {
+		a, b, want int
+		ok         bool
+	}{
+		{12, 345, 12345, true},
+		{1, 0, 10, true},
+		{0, 7, 7, true},
+		{922337203685477580, 7, math.MaxInt, true},
+		{922337203685477580, 8, 0, false},
+		// 10^19 is past an int, but nothing is shifted by it
+		{0, 1000000000000000000, 1000000000000000000, true},
+		{1, 1000000000000000000, 0, false},
+		{math.MaxInt, 1, 0, false},
+	}
+	for _, test := range tests {
+		if got, ok := CheckedConcat(test.a, test.b); got != test.want || ok != test.ok {
+			t.Errorf("CheckedConcat(%d, %d) = %d, %t, want %d, %t", test.a, test.b, got, ok, test.want, test.ok)
+		}
+	}
+
+	for n := range 19 {
+		if got, ok := CheckedPow10(n); !ok || got != Pow10(n) {
+			t.Errorf("CheckedPow10(%d) = %d, %t, want %d", n, got, ok, Pow10(n))
+		}
+	}
+	if got, ok := CheckedPow10(19); ok {
+		t.Errorf("CheckedPow10(19) = %d, want an overflow", got)
+	}
+}
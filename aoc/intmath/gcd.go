@@ -0,0 +1,145 @@
+// Package intmath provides exact integer arithmetic: number theory helpers,
+// digit manipulation, rational numbers and integer linear system solvers.
+package intmath
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+func Abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Mod returns a modulo m in the range [0, |m|), unlike the % operator which
+// keeps the sign of a. Modulo 0, where congruence is equality, it is a.
+func Mod(a, m int) int {
+	if m == 0 {
+		return a
+	}
+	r := a % m
+	if r < 0 {
+		// |m| would overflow for math.MinInt
+		if m < 0 {
+			return r - m
+		}
+		return r + m
+	}
+	return r
+}
+
+func GCD(a, b int) int {
+	a, b = Abs(a), Abs(b)
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// LCM returns the least common multiple of a and b, or 0 if either is 0. It
+// wraps if the multiple does not fit in an int, see CheckedLCM.
+func LCM(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return Abs(a / GCD(a, b) * b)
+}
+
+// CheckedLCM is LCM reporting false if the multiple does not fit in an int.
+func CheckedLCM(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	multiple, ok := CheckedMul(a/GCD(a, b), b)
+	if !ok || multiple == math.MinInt {
+		return 0, false
+	}
+	return Abs(multiple), true
+}
+
+// ExtendedGCD returns g = gcd(a, b) together with Bézout coefficients x and y
+// such that a*x + b*y = g.
+func ExtendedGCD(a, b int) (g, x, y int) {
+	oldR, r := a, b
+	oldX, x := 1, 0
+	oldY, y := 0, 1
+	for r != 0 {
+		q := oldR / r
+		oldR, r = r, oldR-q*r
+		oldX, x = x, oldX-q*x
+		oldY, y = y, oldY-q*y
+	}
+	if oldR < 0 {
+		return -oldR, -oldX, -oldY
+	}
+	return oldR, oldX, oldY
+}
+
+var ErrNoInverse = errors.New("no modular inverse")
+
+// ModInverse returns x in [0, m) with a*x ≡ 1 (mod m).
+func ModInverse(a, m int) (int, error) {
+	if m == 0 {
+		return 0, fmt.Errorf("%w: modulus is zero", ErrNoInverse)
+	}
+	g, x, _ := ExtendedGCD(Mod(a, m), Abs(m))
+	if g != 1 {
+		return 0, fmt.Errorf("%w: gcd(%d, %d) = %d", ErrNoInverse, a, m, g)
+	}
+	return Mod(x, m), nil
+}
+
+var ErrNoSolution = errors.New("no solution")
+
+// CRT solves the system x ≡ residues[i] (mod moduli[i]) with the Chinese
+// Remainder Theorem. Moduli don't need to be pairwise coprime. It returns the
+// smallest non-negative solution and the modulus of the combined congruence,
+// or ErrOverflow if that modulus does not fit in an int.
+func CRT(residues, moduli []int) (x, m int, err error) {
+	if len(residues) != len(moduli) {
+		return 0, 0, fmt.Errorf("residues and moduli are not the same length")
+	}
+
+	x, m = 0, 1
+	for i := range residues {
+		mi := Abs(moduli[i])
+		if mi == 0 {
+			return 0, 0, fmt.Errorf("modulus %d is zero", i)
+		}
+		if mi < 0 {
+			// -math.MinInt
+			return 0, 0, fmt.Errorf("%w: modulus %d is %d", ErrOverflow, i, moduli[i])
+		}
+		ri := Mod(residues[i], mi)
+
+		g, p, _ := ExtendedGCD(m, mi)
+		if (ri-x)%g != 0 {
+			return 0, 0, fmt.Errorf("%w: x ≡ %d (mod %d) contradicts x ≡ %d (mod %d)", ErrNoSolution, ri, mi, x, m)
+		}
+
+		step := mi / g
+		t := mulMod(Mod((ri-x)/g, step), Mod(p, step), step)
+		combined, okModulus := CheckedMul(m, step)
+		mt, okProduct := CheckedMul(m, t)
+		sum, okSum := CheckedAdd(x, mt)
+		if !okModulus || !okProduct || !okSum {
+			return 0, 0, fmt.Errorf("%w: combining modulus %d with %d", ErrOverflow, mi, m)
+		}
+		x, m = Mod(sum, combined), combined
+	}
+
+	return x, m, nil
+}
+
+// mulMod returns a*b mod m for non-negative a, b and positive m without
+// overflowing the intermediate product.
+func mulMod(a, b, m int) int {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	_, rem := bits.Div64(hi%uint64(m), lo, uint64(m))
+	return int(rem)
+}
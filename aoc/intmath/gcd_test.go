@@ -0,0 +1,82 @@
+package intmath
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCRT(t *testing.T) {
+	tests := []struct {
+		residues, moduli []int
+		x, m             int
+		err              error
+	}{
+		{[]int{2, 3, 2}, []int{3, 5, 7}, 23, 105, nil},
+		// moduli sharing a factor
+		{[]int{3, 5}, []int{4, 6}, 11, 12, nil},
+		{[]int{-1, 5}, []int{-4, 6}, 11, 12, nil},
+		{[]int{1, 2}, []int{4, 6}, 0, 0, ErrNoSolution},
+		// two primes below 2^31, whose product fits
+		{[]int{12345, 67890}, []int{2147483647, 2147483629}, 768620956022918873, 4611685975477714963, nil},
+		// two primes below 2^32, whose product doesn't
+		{[]int{1, 2}, []int{4294967291, 4294967279}, 0, 0, ErrOverflow},
+		{[]int{0}, []int{math.MinInt}, 0, 0, ErrOverflow},
+	}
+	for _, test := range tests {
+		x, m, err := CRT(test.residues, test.moduli)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("CRT(%v, %v): got %d, %d, %v, want error %v", test.residues, test.moduli, x, m, err, test.err)
+			}
+			continue
+		}
+		if err != nil || x != test.x || m != test.m {
+			t.Errorf("CRT(%v, %v) = %d, %d, %v, want %d, %d", test.residues, test.moduli, x, m, err, test.x, test.m)
+		}
+		for i := range test.moduli {
+			if Mod(x, test.moduli[i]) != Mod(test.residues[i], test.moduli[i]) {
+				t.Errorf("CRT(%v, %v): %d is not %d modulo %d", test.residues, test.moduli, x, test.residues[i], test.moduli[i])
+			}
+		}
+	}
+}
+
+func TestMod(t *testing.T) {
+	tests := []struct{ a, m, want int }{
+		{7, 3, 1}, {-7, 3, 2}, {7, -3, 1}, {-7, -3, 2}, {-6, 3, 0},
+		{5, 0, 5}, {-5, 0, -5},
+		{-1, math.MinInt, math.MaxInt}, {math.MinInt, math.MinInt, 0},
+		{math.MinInt, math.MaxInt, math.MaxInt - 1},
+	}
+	for _, test := range tests {
+		if got := Mod(test.a, test.m); got != test.want {
+			t.Errorf("Mod(%d, %d) = %d, want %d", test.a, test.m, got, test.want)
+		}
+	}
+}
+
+func TestCheckedLCM(t *testing.T) {
+	tests := []struct {
+		a, b, want int
+		ok         bool
+	}{
+		{4, 6, 12, true},
+		{-4, 6, 12, true},
+		{0, 6, 0, true},
+		// the width and height of the day 14 grid
+		{101, 103, 10403, true},
+		{1 << 40, 3 << 40, 3 << 40, true},
+		{4294967291, 4294967279, 0, false},
+		{math.MinInt, 1, 0, false},
+		{math.MinInt, math.MinInt, 0, false},
+	}
+	for _, test := range tests {
+		if got, ok := CheckedLCM(test.a, test.b); got != test.want || ok != test.ok {
+			t.Errorf("CheckedLCM(%d, %d) = %d, %t, want %d, %t", test.a, test.b, got, ok, test.want, test.ok)
+		}
+		if test.ok && LCM(test.a, test.b) != test.want {
+			t.Errorf("LCM(%d, %d) = %d, want %d", test.a, test.b, LCM(test.a, test.b), test.want)
+		}
+	}
+}
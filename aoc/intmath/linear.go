@@ -0,0 +1,120 @@
+package intmath
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrSingular = errors.New("singular system")
+
+// Solve2 solves the system
+//
+//	a*x + b*y = e
+//	c*x + d*y = f
+//
+// exactly with Cramer's rule. It fails with ErrOverflow instead of returning
+// a wrong answer when an intermediate product does not fit in an int.
+func Solve2(a, b, c, d, e, f int) (x, y Rat, err error) {
+	det, ok := cross(a, b, c, d)
+	if !ok {
+		return Rat{}, Rat{}, ErrOverflow
+	}
+	if det == 0 {
+		return Rat{}, Rat{}, ErrSingular
+	}
+
+	xNum, ok := cross(e, b, f, d)
+	if !ok {
+		return Rat{}, Rat{}, ErrOverflow
+	}
+	yNum, ok := cross(a, e, c, f)
+	if !ok {
+		return Rat{}, Rat{}, ErrOverflow
+	}
+
+	return NewRat(xNum, det), NewRat(yNum, det), nil
+}
+
+// SolveInt2 is Solve2 restricted to integer solutions. It fails with
+// ErrNoSolution when the unique solution is not integral.
+func SolveInt2(a, b, c, d, e, f int) (x, y int, err error) {
+	rx, ry, err := Solve2(a, b, c, d, e, f)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !rx.IsInt() || !ry.IsInt() {
+		return 0, 0, fmt.Errorf("%w in integers: x = %v, y = %v", ErrNoSolution, rx, ry)
+	}
+	return rx.Num(), ry.Num(), nil
+}
+
+// Solve solves the square system matrix * x = vector exactly using
+// fraction-free (Bareiss) elimination, so every intermediate value stays an
+// integer until the final back substitution. Like Solve2 it fails with
+// ErrOverflow when an intermediate value does not fit in an int.
+func Solve(matrix [][]int, vector []int) ([]Rat, error) {
+	n := len(matrix)
+	if len(vector) != n {
+		return nil, fmt.Errorf("matrix has %d rows but vector has %d values", n, len(vector))
+	}
+
+	augmented := make([][]int, n)
+	for i, row := range matrix {
+		if len(row) != n {
+			return nil, fmt.Errorf("row %d has %d columns, expected %d", i, len(row), n)
+		}
+		augmented[i] = make([]int, n+1)
+		copy(augmented[i], row)
+		augmented[i][n] = vector[i]
+	}
+
+	previousPivot := 1
+	for k := 0; k < n; k++ {
+		if augmented[k][k] == 0 {
+			swapped := false
+			for i := k + 1; i < n; i++ {
+				if augmented[i][k] != 0 {
+					augmented[k], augmented[i] = augmented[i], augmented[k]
+					swapped = true
+					break
+				}
+			}
+			if !swapped {
+				return nil, ErrSingular
+			}
+		}
+
+		for i := k + 1; i < n; i++ {
+			for j := k + 1; j <= n; j++ {
+				// the division is exact, and only overflows for math.MinInt / -1
+				v, ok := cross(augmented[i][j], augmented[i][k], augmented[k][j], augmented[k][k])
+				if !ok || (v == math.MinInt && previousPivot == -1) {
+					return nil, ErrOverflow
+				}
+				augmented[i][j] = v / previousPivot
+			}
+			augmented[i][k] = 0
+		}
+		previousPivot = augmented[k][k]
+	}
+
+	solution := make([]Rat, n)
+	for i := n - 1; i >= 0; i-- {
+		sum, ok := Int(augmented[i][n]), true
+		for j := i + 1; j < n && ok; j++ {
+			var term Rat
+			if term, ok = Int(augmented[i][j]).checkedMul(solution[j]); ok {
+				sum, ok = sum.checkedSub(term)
+			}
+		}
+		if ok {
+			solution[i], ok = sum.checkedDiv(Int(augmented[i][i]))
+		}
+		if !ok {
+			return nil, ErrOverflow
+		}
+	}
+
+	return solution, nil
+}
@@ -0,0 +1,114 @@
+package intmath
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+)
+
+// referenceSolve solves matrix * x = vector with math/big Gaussian
+// elimination, reporting false for a singular matrix.
+func referenceSolve(matrix [][]int, vector []int) ([]*big.Rat, bool) {
+	n := len(matrix)
+	rows := make([][]*big.Rat, n)
+	for i := range rows {
+		rows[i] = make([]*big.Rat, n+1)
+		for j := range n {
+			rows[i][j] = new(big.Rat).SetInt64(int64(matrix[i][j]))
+		}
+		rows[i][n] = new(big.Rat).SetInt64(int64(vector[i]))
+	}
+	for k := range n {
+		pivot := k
+		for pivot < n && rows[pivot][k].Sign() == 0 {
+			pivot++
+		}
+		if pivot == n {
+			return nil, false
+		}
+		rows[k], rows[pivot] = rows[pivot], rows[k]
+		for i := range n {
+			if i == k || rows[i][k].Sign() == 0 {
+				continue
+			}
+			factor := new(big.Rat).Quo(rows[i][k], rows[k][k])
+			for j := k; j <= n; j++ {
+				rows[i][j].Sub(rows[i][j], new(big.Rat).Mul(factor, rows[k][j]))
+			}
+		}
+	}
+	solution := make([]*big.Rat, n)
+	for i := range solution {
+		solution[i] = new(big.Rat).Quo(rows[i][n], rows[i][i])
+	}
+	return solution, true
+}
+
+func TestSolveMatchesReference(t *testing.T) {
+	random := rand.New(rand.NewPCG(27, 42))
+	for range 500 {
+		n := 1 + random.IntN(4)
+		matrix, vector := make([][]int, n), make([]int, n)
+		for i := range matrix {
+			matrix[i] = make([]int, n)
+			for j := range matrix[i] {
+				// small values and many zeros, for pivot swaps and singular matrices
+				matrix[i][j] = random.IntN(7) - 3
+			}
+			vector[i] = random.IntN(2001) - 1000
+		}
+
+		solution, err := Solve(matrix, vector)
+		want, ok := referenceSolve(matrix, vector)
+		if !ok {
+			if !errors.Is(err, ErrSingular) {
+				t.Errorf("Solve(%v, %v): got %v, %v, want %v", matrix, vector, solution, err, ErrSingular)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Solve(%v, %v): %v", matrix, vector, err)
+		}
+		for i := range solution {
+			got := big.NewRat(int64(solution[i].Num()), int64(solution[i].Den()))
+			if got.Cmp(want[i]) != 0 {
+				t.Errorf("Solve(%v, %v): x%d = %v, want %v", matrix, vector, i, solution[i], want[i].RatString())
+			}
+		}
+	}
+}
+
+func TestSolveOverflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		matrix [][]int
+		vector []int
+	}{
+		{"elimination", [][]int{{math.MaxInt / 2, 3}, {3, math.MaxInt / 2}}, []int{1, 1}},
+		{"elimination with the vector", [][]int{{1 << 40, 0}, {1, 1}}, []int{1, 1 << 40}},
+		{"back substitution", [][]int{{1, math.MaxInt}, {0, 1}}, []int{0, math.MaxInt}},
+	}
+	for _, test := range tests {
+		if solution, err := Solve(test.matrix, test.vector); !errors.Is(err, ErrOverflow) {
+			t.Errorf("%s: got %v, %v, want %v", test.name, solution, err, ErrOverflow)
+		}
+	}
+}
+
+func TestSolve2(t *testing.T) {
+	// the first machine of the day 13 example
+	if x, y, err := SolveInt2(94, 22, 34, 67, 8400, 5400); err != nil || x != 80 || y != 40 {
+		t.Errorf("got %d, %d, %v, want 80, 40", x, y, err)
+	}
+	if _, _, err := SolveInt2(1, 2, 2, 4, 3, 6); !errors.Is(err, ErrSingular) {
+		t.Errorf("singular: got %v, want %v", err, ErrSingular)
+	}
+	if _, _, err := SolveInt2(2, 0, 0, 2, 1, 1); !errors.Is(err, ErrNoSolution) {
+		t.Errorf("fractional: got %v, want %v", err, ErrNoSolution)
+	}
+	if _, _, err := Solve2(math.MaxInt, 2, 2, math.MaxInt, 1, 1); !errors.Is(err, ErrOverflow) {
+		t.Errorf("overflow: got %v, want %v", err, ErrOverflow)
+	}
+}
@@ -0,0 +1,123 @@
+package intmath
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+var ErrOverflow = errors.New("integer overflow")
+
+// CheckedAdd returns a+b and false if the sum does not fit in an int.
+func CheckedAdd(a, b int) (int, bool) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, false
+	}
+	return sum, true
+}
+
+// CheckedSub returns a-b and false if the difference does not fit in an int.
+func CheckedSub(a, b int) (int, bool) {
+	difference := a - b
+	if (b > 0 && difference > a) || (b < 0 && difference < a) {
+		return 0, false
+	}
+	return difference, true
+}
+
+// CheckedMul returns a*b and false if the product does not fit in an int.
+func CheckedMul(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	if (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return 0, false
+	}
+	product := a * b
+	if product/b != a {
+		return 0, false
+	}
+	return product, true
+}
+
+// cross returns a*d - b*c, the determinant of [[a, b], [c, d]].
+func cross(a, b, c, d int) (int, bool) {
+	ad, ok := CheckedMul(a, d)
+	if !ok {
+		return 0, false
+	}
+	bc, ok := CheckedMul(b, c)
+	if !ok {
+		return 0, false
+	}
+	return CheckedSub(ad, bc)
+}
+
+// Sum accumulates ints exactly, switching to math/big once the total no
+// longer fits in an int. The zero value is an empty sum.
+type Sum struct {
+	small int
+	big   *big.Int
+}
+
+func (s *Sum) Add(n int) {
+	if s.big == nil {
+		if sum, ok := CheckedAdd(s.small, n); ok {
+			s.small = sum
+			return
+		}
+		s.promote()
+	}
+	s.big.Add(s.big, big.NewInt(int64(n)))
+}
+
+// AddProduct adds the product of factors, which may itself overflow.
+func (s *Sum) AddProduct(factors ...int) {
+	product, ok := 1, true
+	for _, factor := range factors {
+		if product, ok = CheckedMul(product, factor); !ok {
+			break
+		}
+	}
+	if ok {
+		s.Add(product)
+		return
+	}
+
+	s.promote()
+	exact := big.NewInt(1)
+	for _, factor := range factors {
+		exact.Mul(exact, big.NewInt(int64(factor)))
+	}
+	s.big.Add(s.big, exact)
+}
+
+// AddDistance adds n times |a-b|, where the distance may itself overflow.
+func (s *Sum) AddDistance(a, b, n int) {
+	if a < b {
+		a, b = b, a
+	}
+	if distance, ok := CheckedSub(a, b); ok {
+		s.AddProduct(distance, n)
+		return
+	}
+	s.promote()
+	distance := new(big.Int).Sub(big.NewInt(int64(a)), big.NewInt(int64(b)))
+	s.big.Add(s.big, distance.Mul(distance, big.NewInt(int64(n))))
+}
+
+// promote switches the sum to math/big.
+func (s *Sum) promote() {
+	if s.big == nil {
+		s.big = big.NewInt(int64(s.small))
+	}
+}
+
+func (s *Sum) String() string {
+	if s.big == nil {
+		return strconv.Itoa(s.small)
+	}
+	return s.big.String()
+}
@@ -0,0 +1,30 @@
+package intmath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSumAddProduct(t *testing.T) {
+	tests := []struct {
+		name    string
+		factors [][]int
+		want    string
+	}{
+		{"fits", [][]int{{2, 3, 4}, {5}}, "29"},
+		{"no factors", [][]int{{}}, "1"},
+		{"product of the first two overflows", [][]int{{1 << 32, 1 << 32, 1}}, "18446744073709551616"},
+		{"product overflows on the last", [][]int{{3, 1 << 31, 1 << 31}}, "13835058055282163712"},
+		{"negative", [][]int{{math.MinInt, -1, 1}, {1}}, "9223372036854775809"},
+		{"zero after an overflow", [][]int{{math.MaxInt, 2, 0}}, "0"},
+	}
+	for _, test := range tests {
+		var sum Sum
+		for _, factors := range test.factors {
+			sum.AddProduct(factors...)
+		}
+		if got := sum.String(); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
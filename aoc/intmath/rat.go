@@ -0,0 +1,117 @@
+package intmath
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rat is an exact rational number num/den kept in lowest terms with a positive
+// denominator. The zero value is 0. Arithmetic on Rat is not overflow-checked.
+type Rat struct {
+	num, den int
+}
+
+// NewRat returns num/den. It panics if den is zero.
+func NewRat(num, den int) Rat {
+	if den == 0 {
+		panic("intmath: zero denominator")
+	}
+	if den < 0 {
+		num, den = -num, -den
+	}
+	g := GCD(num, den)
+	return Rat{num: num / g, den: den / g}
+}
+
+// Int returns n as a rational number.
+func Int(n int) Rat {
+	return Rat{num: n, den: 1}
+}
+
+func (r Rat) Num() int {
+	return r.num
+}
+
+func (r Rat) Den() int {
+	if r.den == 0 {
+		return 1
+	}
+	return r.den
+}
+
+func (r Rat) IsInt() bool {
+	return r.Den() == 1
+}
+
+func (r Rat) Add(o Rat) Rat {
+	return NewRat(r.num*o.Den()+o.num*r.Den(), r.Den()*o.Den())
+}
+
+func (r Rat) Sub(o Rat) Rat {
+	return r.Add(o.Neg())
+}
+
+func (r Rat) Mul(o Rat) Rat {
+	return NewRat(r.num*o.num, r.Den()*o.Den())
+}
+
+// Div returns r/o. It panics if o is zero.
+func (r Rat) Div(o Rat) Rat {
+	return NewRat(r.num*o.Den(), r.Den()*o.num)
+}
+
+func (r Rat) Neg() Rat {
+	return Rat{num: -r.num, den: r.Den()}
+}
+
+// Cmp returns -1, 0 or 1 depending on whether r is less than, equal to or
+// greater than o.
+func (r Rat) Cmp(o Rat) int {
+	left, right := r.num*o.Den(), o.num*r.Den()
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	}
+	return 0
+}
+
+func (r Rat) String() string {
+	if r.IsInt() {
+		return fmt.Sprintf("%d", r.num)
+	}
+	return fmt.Sprintf("%d/%d", r.num, r.den)
+}
+
+// checkedRat returns num/den like NewRat for a non-zero den, and false if
+// either does not fit in an int or is math.MinInt, which has no negation.
+func checkedRat(num, den int, ok bool) (Rat, bool) {
+	if !ok || num == math.MinInt || den == math.MinInt {
+		return Rat{}, false
+	}
+	return NewRat(num, den), true
+}
+
+// checkedSub is Sub, reporting false on overflow.
+func (r Rat) checkedSub(o Rat) (Rat, bool) {
+	left, okLeft := CheckedMul(r.num, o.Den())
+	right, okRight := CheckedMul(o.num, r.Den())
+	num, okNum := CheckedSub(left, right)
+	den, okDen := CheckedMul(r.Den(), o.Den())
+	return checkedRat(num, den, okLeft && okRight && okNum && okDen)
+}
+
+// checkedMul is Mul, reporting false on overflow.
+func (r Rat) checkedMul(o Rat) (Rat, bool) {
+	num, okNum := CheckedMul(r.num, o.num)
+	den, okDen := CheckedMul(r.Den(), o.Den())
+	return checkedRat(num, den, okNum && okDen)
+}
+
+// checkedDiv is Div, reporting false on overflow. It panics if o is zero.
+func (r Rat) checkedDiv(o Rat) (Rat, bool) {
+	num, okNum := CheckedMul(r.num, o.Den())
+	den, okDen := CheckedMul(r.Den(), o.num)
+	return checkedRat(num, den, okNum && okDen)
+}
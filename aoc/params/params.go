@@ -0,0 +1,98 @@
+// Package params lets a day declare named parameters with defaults, which can
+// be overridden on the command line with repeated -param key=value flags.
+//
+// Days declare parameters at package level, much like the flag package:
+//
+//	var width = params.Int("width", 101, "width of the space")
+//
+// and call Parse instead of flag.Parse at the start of main.
+package params
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Param describes a declared parameter.
+type Param struct {
+	Name    string `json:"name"`
+	Default string `json:"default"`
+	Usage   string `json:"usage"`
+}
+
+var (
+	set       = flag.NewFlagSet("params", flag.ContinueOnError)
+	overrides assignments
+	list      bool
+)
+
+// Int declares an int parameter with a default value and a usage string, and
+// returns where its value is stored once Parse has run.
+func Int(name string, value int, usage string) *int {
+	return set.Int(name, value, usage)
+}
+
+// String declares a string parameter like Int.
+func String(name string, value string, usage string) *string {
+	return set.String(name, value, usage)
+}
+
+// Bool declares a bool parameter like Int. Its value is set with
+// -param name=true or -param name=false, a bare -param name is not accepted.
+func Bool(name string, value bool, usage string) *bool {
+	return set.Bool(name, value, usage)
+}
+
+// Parse parses the command line and applies -param overrides to the declared
+// parameters. With -list-params it prints the declared parameters and exits.
+// It must be called instead of flag.Parse.
+func Parse() error {
+	flag.Var(&overrides, "param", "set a day parameter as `key=value` (repeatable)")
+	flag.BoolVar(&list, "list-params", false, "print the declared parameters as JSON and exit")
+	flag.Parse()
+
+	if list {
+		if err := json.NewEncoder(os.Stdout).Encode(List()); err != nil {
+			return fmt.Errorf("error listing parameters: %w", err)
+		}
+		os.Exit(0)
+	}
+
+	for _, assignment := range overrides {
+		key, value, _ := strings.Cut(assignment, "=")
+		if set.Lookup(key) == nil {
+			return fmt.Errorf("unknown parameter %q", key)
+		}
+		if err := set.Set(key, value); err != nil {
+			return fmt.Errorf("invalid value for parameter %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// List returns the declared parameters in lexical order.
+func List() []Param {
+	params := make([]Param, 0)
+	set.VisitAll(func(f *flag.Flag) {
+		params = append(params, Param{Name: f.Name, Default: f.DefValue, Usage: f.Usage})
+	})
+	return params
+}
+
+type assignments []string
+
+func (a *assignments) String() string {
+	return strings.Join(*a, ",")
+}
+
+func (a *assignments) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	*a = append(*a, value)
+	return nil
+}
@@ -0,0 +1,118 @@
+package puzzle
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	statementFile = "statement.html"
+	markdownFile  = "statement.md"
+)
+
+// Cache keeps the fetched statements, one directory per puzzle, and
+// leaderboards, both per website.
+type Cache struct {
+	Dir string
+}
+
+// DefaultCache returns the cache in the user cache directory, next to the
+// binaries built by the runner.
+func DefaultCache() Cache {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return Cache{Dir: filepath.Join(cacheDir, "aoc")}
+}
+
+// hostEscaper keeps the host of a base URL a single file name.
+var hostEscaper = strings.NewReplacer(":", "_", "/", "_", `\`, "_")
+
+// host returns the directory of the website at baseURL in the cache, so that
+// what a local stub serves doesn't stand in for the real website.
+func host(baseURL string) string {
+	host := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return hostEscaper.Replace(host)
+}
+
+func (c Cache) path(baseURL string, year, day int, name string) string {
+	return filepath.Join(c.Dir, "puzzles", host(baseURL), strconv.Itoa(year), strconv.Itoa(day), name)
+}
+
+// Statement returns the HTML page of year/day cached from the website at
+// baseURL, the error wrapping os.ErrNotExist if it was never fetched.
+func (c Cache) Statement(baseURL string, year, day int) (string, error) {
+	page, err := os.ReadFile(c.path(baseURL, year, day, statementFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("no statement of %d/%d cached: %w", year, day, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading cached statement: %w", err)
+	}
+	return string(page), nil
+}
+
+// MarkdownPath returns where the Markdown of the statement of year/day from
+// the website at baseURL is cached.
+func (c Cache) MarkdownPath(baseURL string, year, day int) string {
+	return c.path(baseURL, year, day, markdownFile)
+}
+
+// Save caches the HTML page of year/day fetched from the website at baseURL
+// along with its Markdown conversion.
+func (c Cache) Save(baseURL string, year, day int, page, markdown string) error {
+	dir := filepath.Dir(c.path(baseURL, year, day, statementFile))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating cache directory: %w", err)
+	}
+	if err := os.WriteFile(c.path(baseURL, year, day, statementFile), []byte(page), 0o644); err != nil {
+		return fmt.Errorf("error caching statement: %w", err)
+	}
+	if err := os.WriteFile(c.MarkdownPath(baseURL, year, day), []byte(markdown), 0o644); err != nil {
+		return fmt.Errorf("error caching statement: %w", err)
+	}
+	return nil
+}
+
+func (c Cache) leaderboardPath(baseURL string, year int, id string) string {
+	return filepath.Join(c.Dir, "leaderboards", host(baseURL), strconv.Itoa(year), id+".json")
+}
+
+// Leaderboard returns the JSON of the private leaderboard id of year cached
+// from the website at baseURL and when it was fetched, reporting false if it
+// is missing or older than ttl.
+func (c Cache) Leaderboard(baseURL string, year int, id string, ttl time.Duration) ([]byte, time.Time, bool) {
+	path := c.leaderboardPath(baseURL, year, id)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > ttl {
+		return nil, time.Time{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return data, info.ModTime(), true
+}
+
+// SaveLeaderboard caches the JSON of the private leaderboard id of year
+// fetched from the website at baseURL.
+func (c Cache) SaveLeaderboard(baseURL string, year int, id string, data []byte) error {
+	path := c.leaderboardPath(baseURL, year, id)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("error caching leaderboard: %w", err)
+	}
+	return nil
+}
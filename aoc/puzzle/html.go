@@ -0,0 +1,402 @@
+package puzzle
+
+import (
+	"bytes"
+	"html"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Markdown converts the puzzle articles of a statement page to Markdown.
+// Relative links are resolved against pageURL. Everything outside the
+// articles, such as the answer form, is dropped.
+func Markdown(page, pageURL string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		base = nil
+	}
+	return render(page, base, markdownStyle)
+}
+
+// Terminal formats the puzzle articles of a statement page for a terminal,
+// with ANSI escape sequences for emphasis and code.
+func Terminal(page string) string {
+	return render(page, nil, terminalStyle)
+}
+
+// Examples returns the text of the preformatted blocks of the puzzle articles,
+// which hold the example inputs along with intermediate states of the
+// examples.
+func Examples(page string) []string {
+	var examples []string
+	var example strings.Builder
+	inArticle, inPre := false, false
+	for _, t := range tokenize(page) {
+		switch {
+		case t.tag == "article":
+			inArticle = !t.closing
+		case t.tag == "pre" && inArticle:
+			inPre = !t.closing
+			if t.closing {
+				examples = append(examples, example.String())
+				example.Reset()
+			}
+		case t.tag == "" && inPre:
+			example.WriteString(t.text)
+		}
+	}
+	return examples
+}
+
+// style says how the elements of a statement are written out.
+type style struct {
+	// heading, emphasis and code hold the opening and closing markers
+	heading, emphasis, code [2]string
+	// fence opens and closes preformatted blocks, whose lines start with indent
+	fence  [2]string
+	indent string
+	// emphasisInCode keeps emphasis inside code, which Markdown can't express
+	emphasisInCode bool
+	escape         func(text string) string
+	link           func(text, href string) string
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`)
+
+var markdownStyle = style{
+	heading:  [2]string{"## ", ""},
+	emphasis: [2]string{"*", "*"},
+	code:     [2]string{"`", "`"},
+	fence:    [2]string{"```\n", "```"},
+	escape:   markdownEscaper.Replace,
+	link: func(text, href string) string {
+		return "[" + text + "](" + href + ")"
+	},
+}
+
+var terminalStyle = style{
+	heading:        [2]string{"\x1b[1m", "\x1b[0m"},
+	emphasis:       [2]string{"\x1b[1m", "\x1b[22m"},
+	code:           [2]string{"\x1b[36m", "\x1b[39m"},
+	indent:         "    ",
+	emphasisInCode: true,
+	escape:         func(text string) string { return text },
+	link: func(text, href string) string {
+		return "\x1b[4m" + text + "\x1b[24m"
+	},
+}
+
+func render(page string, base *url.URL, s style) string {
+	r := renderer{style: s, base: base, lineStart: true}
+	inArticle := false
+	for _, t := range tokenize(page) {
+		if t.tag == "article" {
+			inArticle = !t.closing
+			r.block()
+			continue
+		}
+		if inArticle {
+			r.token(t)
+		}
+	}
+
+	text := strings.TrimSpace(r.out.String())
+	if text == "" {
+		return ""
+	}
+	return text + "\n"
+}
+
+type renderer struct {
+	style style
+	base  *url.URL
+	out   bytes.Buffer
+
+	pre, code bool
+	lists     int
+	// lineStart drops whitespace at the start of blocks and list items, space
+	// holds back whitespace until the next word
+	lineStart, space bool
+	// start is where the text of the open heading, link or preformatted
+	// block begins in out, rewritten once the element closes
+	start int
+	href  string
+}
+
+func (r *renderer) token(t token) {
+	switch t.tag {
+	case "":
+		r.text(t.text)
+	case "h1", "h2", "h3":
+		if !t.closing {
+			r.block()
+			r.start = r.out.Len()
+			return
+		}
+		// puzzle titles read "--- Day 13: Claw Contraption ---"
+		title := strings.Trim(r.cut(), "- ")
+		r.out.WriteString(r.style.heading[0] + title + r.style.heading[1])
+		r.block()
+	case "p":
+		r.block()
+	case "ul", "ol":
+		if t.closing {
+			r.lists--
+		} else {
+			r.lists++
+		}
+		if r.lists == 0 || (r.lists == 1 && !t.closing) {
+			r.block()
+		}
+	case "li":
+		if !t.closing {
+			r.newline()
+			r.out.WriteString(strings.Repeat("  ", max(r.lists-1, 0)) + "- ")
+		}
+	case "pre":
+		if !t.closing {
+			r.block()
+			r.pre = true
+			r.start = r.out.Len()
+			return
+		}
+		r.pre = false
+		lines := strings.Split(strings.TrimSuffix(r.cut(), "\n"), "\n")
+		r.out.WriteString(r.style.fence[0])
+		for _, line := range lines {
+			if line != "" {
+				line = r.style.indent + line
+			}
+			r.out.WriteString(line + "\n")
+		}
+		r.out.WriteString(r.style.fence[1])
+		r.block()
+	case "code":
+		if r.pre {
+			return
+		}
+		r.code = !t.closing
+		r.marker(r.style.code, t.closing)
+	case "em":
+		if (r.pre || r.code) && !r.style.emphasisInCode {
+			return
+		}
+		r.marker(r.style.emphasis, t.closing)
+	case "a":
+		if !t.closing {
+			r.flushSpace()
+			r.start = r.out.Len()
+			r.href = r.resolve(t.attrs["href"])
+			return
+		}
+		r.out.WriteString(r.style.link(r.cut(), r.href))
+	case "br":
+		r.newline()
+	}
+}
+
+func (r *renderer) text(text string) {
+	if r.pre {
+		r.out.WriteString(text)
+		return
+	}
+
+	// whitespace collapses as in HTML
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		r.space = r.space || text != ""
+		return
+	}
+	r.space = r.space || unicode.IsSpace(rune(text[0]))
+	for _, field := range fields {
+		r.flushSpace()
+		if !r.code {
+			field = r.style.escape(field)
+		}
+		r.out.WriteString(field)
+		r.space = true
+	}
+	r.space = unicode.IsSpace(rune(text[len(text)-1]))
+}
+
+// marker writes the opening or closing marker of an inline element. Pending
+// whitespace goes before an opening marker and after a closing one.
+func (r *renderer) marker(markers [2]string, closing bool) {
+	if closing {
+		r.out.WriteString(markers[1])
+		return
+	}
+	r.flushSpace()
+	r.out.WriteString(markers[0])
+}
+
+func (r *renderer) flushSpace() {
+	if r.space && !r.lineStart {
+		r.out.WriteByte(' ')
+	}
+	r.space, r.lineStart = false, false
+}
+
+// block ends the current block with a blank line.
+func (r *renderer) block() {
+	text := r.out.Bytes()
+	switch {
+	case len(text) == 0, bytes.HasSuffix(text, []byte("\n\n")):
+	case bytes.HasSuffix(text, []byte("\n")):
+		r.out.WriteByte('\n')
+	default:
+		r.out.WriteString("\n\n")
+	}
+	r.lineStart, r.space = true, false
+}
+
+func (r *renderer) newline() {
+	if text := r.out.Bytes(); len(text) > 0 && text[len(text)-1] != '\n' {
+		r.out.WriteByte('\n')
+	}
+	r.lineStart, r.space = true, false
+}
+
+// cut removes and returns the output since start.
+func (r *renderer) cut() string {
+	text := string(r.out.Bytes()[r.start:])
+	r.out.Truncate(r.start)
+	return text
+}
+
+func (r *renderer) resolve(href string) string {
+	if r.base == nil {
+		return href
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return r.base.ResolveReference(ref).String()
+}
+
+// token is a tag or a run of text of an HTML page.
+type token struct {
+	// tag is the lower case name of the tag, empty for text
+	tag     string
+	closing bool
+	attrs   map[string]string
+	// text is the unescaped text
+	text string
+}
+
+// tokenize splits an HTML page into tags and text, dropping comments,
+// doctypes and the contents of scripts and styles. It is no HTML parser but
+// copes with the puzzle pages.
+func tokenize(page string) []token {
+	var tokens []token
+	for page != "" {
+		lt := strings.IndexByte(page, '<')
+		if lt != 0 {
+			if lt < 0 {
+				lt = len(page)
+			}
+			tokens = append(tokens, token{text: html.UnescapeString(page[:lt])})
+			page = page[lt:]
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(page, "<!--"); ok {
+			_, page, _ = strings.Cut(rest, "-->")
+			continue
+		}
+
+		end := tagEnd(page)
+		if end < 0 {
+			tokens = append(tokens, token{text: html.UnescapeString(page)})
+			break
+		}
+		t, ok := parseTag(page[1:end])
+		page = page[end+1:]
+		if !ok {
+			continue
+		}
+		tokens = append(tokens, t)
+
+		if !t.closing && (t.tag == "script" || t.tag == "style") {
+			i := strings.Index(strings.ToLower(page), "</"+t.tag)
+			if i < 0 {
+				break
+			}
+			page = page[i:]
+		}
+	}
+	return tokens
+}
+
+// tagEnd returns the index of the '>' closing the tag that page starts with,
+// or -1 if there is none.
+func tagEnd(page string) int {
+	var quote byte
+	for i := 1; i < len(page); i++ {
+		switch c := page[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i
+		}
+	}
+	return -1
+}
+
+// parseTag parses the inside of a tag, reporting false for doctypes and other
+// declarations.
+func parseTag(s string) (token, bool) {
+	var t token
+	s, t.closing = strings.CutPrefix(s, "/")
+	s = strings.TrimSuffix(s, "/")
+
+	nameEnd := strings.IndexFunc(s, unicode.IsSpace)
+	if nameEnd < 0 {
+		nameEnd = len(s)
+	}
+	t.tag = strings.ToLower(s[:nameEnd])
+	if t.tag == "" || t.tag[0] < 'a' || t.tag[0] > 'z' {
+		return t, false
+	}
+
+	t.attrs = parseAttrs(s[nameEnd:])
+	return t, true
+}
+
+func parseAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return attrs
+		}
+
+		nameEnd := strings.IndexFunc(s, func(r rune) bool { return r == '=' || unicode.IsSpace(r) })
+		if nameEnd < 0 {
+			nameEnd = len(s)
+		}
+		name := strings.ToLower(s[:nameEnd])
+		s = strings.TrimLeftFunc(s[nameEnd:], unicode.IsSpace)
+
+		value := ""
+		if rest, ok := strings.CutPrefix(s, "="); ok {
+			rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+			if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+				value, s, _ = strings.Cut(rest[1:], rest[:1])
+			} else {
+				valueEnd := strings.IndexFunc(rest, unicode.IsSpace)
+				if valueEnd < 0 {
+					valueEnd = len(rest)
+				}
+				value, s = rest[:valueEnd], rest[valueEnd:]
+			}
+		}
+		attrs[name] = html.UnescapeString(value)
+	}
+}
@@ -0,0 +1,110 @@
+package puzzle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Leaderboard is a private leaderboard as served by the website.
+type Leaderboard struct {
+	Event   string            `json:"event"`
+	OwnerID int               `json:"owner_id"`
+	Members map[string]Member `json:"members"`
+}
+
+type Member struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Stars      int    `json:"stars"`
+	LocalScore int    `json:"local_score"`
+	LastStar   int64  `json:"last_star_ts"`
+	// Days maps the day, then the part ("1" or "2"), to the star earned.
+	Days map[string]map[string]Star `json:"completion_day_level"`
+}
+
+type Star struct {
+	// Time is when the star was earned, in seconds since the Unix epoch.
+	Time  int64 `json:"get_star_ts"`
+	Index int   `json:"star_index"`
+}
+
+// LeaderboardURL returns the JSON of the private leaderboard id of year.
+func (c *Client) LeaderboardURL(year int, id string) string {
+	return fmt.Sprintf("%s/%d/leaderboard/private/view/%s.json", c.BaseURL, year, id)
+}
+
+// Leaderboard downloads the JSON of the private leaderboard id of year, which
+// only its members can see. The website asks not to do so more than once
+// every 15 minutes.
+func (c *Client) Leaderboard(ctx context.Context, year int, id string) ([]byte, error) {
+	if c.Session == "" {
+		return nil, ErrNoSession
+	}
+	return c.get(ctx, c.LeaderboardURL(year, id))
+}
+
+func ParseLeaderboard(data []byte) (Leaderboard, error) {
+	var leaderboard Leaderboard
+	if err := json.Unmarshal(data, &leaderboard); err != nil {
+		// the website answers a logged out request with a page instead
+		return leaderboard, fmt.Errorf("error parsing leaderboard, check %s: %w", SessionEnv, err)
+	}
+	return leaderboard, nil
+}
+
+// Ranked returns the members by decreasing local score, ties going to the
+// member with more stars and then to the one who earned their last star first.
+func (l Leaderboard) Ranked() []Member {
+	members := make([]Member, 0, len(l.Members))
+	for _, member := range l.Members {
+		members = append(members, member)
+	}
+	slices.SortFunc(members, func(a, b Member) int {
+		switch {
+		case a.LocalScore != b.LocalScore:
+			return b.LocalScore - a.LocalScore
+		case a.Stars != b.Stars:
+			return b.Stars - a.Stars
+		case a.LastStar != b.LastStar:
+			return int(a.LastStar - b.LastStar)
+		}
+		return a.ID - b.ID
+	})
+	return members
+}
+
+// DisplayName returns the name of m as the website shows it.
+func (m Member) DisplayName() string {
+	if m.Name == "" {
+		return fmt.Sprintf("(anonymous user #%d)", m.ID)
+	}
+	return m.Name
+}
+
+// Star returns when m earned the star of part of day, reporting false if they
+// have not.
+func (m Member) Star(day, part int) (time.Time, bool) {
+	star, ok := m.Days[strconv.Itoa(day)][strconv.Itoa(part)]
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(star.Time, 0), true
+}
+
+// Release returns when the puzzle of year/day is released, at midnight US
+// Eastern time.
+func Release(year, day int) time.Time {
+	return time.Date(year, time.December, day, 5, 0, 0, 0, time.UTC)
+}
+
+// Days returns the number of puzzles of the event of year.
+func Days(year int) int {
+	if year >= 2025 {
+		return 12
+	}
+	return 25
+}
@@ -0,0 +1,52 @@
+package puzzle
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func readLeaderboard(t *testing.T) Leaderboard {
+	t.Helper()
+	data, err := os.ReadFile("testdata/leaderboard.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaderboard, err := ParseLeaderboard(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaderboard
+}
+
+func TestRanked(t *testing.T) {
+	// Cy has more stars than Ada and the anonymous user on the same score, who
+	// earned their last star second; Dee and Eve tie on everything
+	want := []string{"Cy", "(anonymous user #2)", "Ada", "Dee", "Eve", "Finn"}
+
+	members := readLeaderboard(t).Ranked()
+	if len(members) != len(want) {
+		t.Fatalf("got %d members, want %d", len(members), len(want))
+	}
+	for i, member := range members {
+		if member.DisplayName() != want[i] {
+			t.Errorf("rank %d: got %s, want %s", i+1, member.DisplayName(), want[i])
+		}
+	}
+}
+
+func TestStar(t *testing.T) {
+	ada := readLeaderboard(t).Members["1"]
+	if got, ok := ada.Star(1, 1); !ok || got.Sub(Release(1999, 1)) != 100*time.Second {
+		t.Errorf("day 1 part 1: got %v, %v, want 100s after the release", got, ok)
+	}
+	if _, ok := ada.Star(3, 1); ok {
+		t.Error("day 3 part 1: got a star, want none")
+	}
+}
+
+func TestParseLeaderboardLoggedOut(t *testing.T) {
+	if _, err := ParseLeaderboard([]byte("<!DOCTYPE html>")); err == nil {
+		t.Error("parsing a page: got no error")
+	}
+}
@@ -0,0 +1,89 @@
+// Package puzzle downloads puzzle statements, inputs and private leaderboards
+// from the Advent of Code website, caches them and converts statements to
+// Markdown.
+//
+// The website lives at a configurable base URL, see config.Config.URL, so
+// that the commands can be pointed at a local stub. Inputs, leaderboards and
+// the second part of statements are only served to a logged in user: their
+// session cookie is read from the AOC_SESSION environment variable.
+package puzzle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SessionEnv names the environment variable holding the session cookie.
+const SessionEnv = "AOC_SESSION"
+
+// ErrNoSession is returned when fetching an input without a session cookie.
+var ErrNoSession = errors.New("no session cookie, set " + SessionEnv + " to the session cookie of adventofcode.com")
+
+type Client struct {
+	// BaseURL is the root of the website, without a trailing slash.
+	BaseURL string
+	// Session is the session cookie, empty when logged out.
+	Session string
+	HTTP    *http.Client
+}
+
+func NewClient(baseURL, session string) *Client {
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Session: session,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// StatementURL returns the page of the puzzle of year/day.
+func (c *Client) StatementURL(year, day int) string {
+	return fmt.Sprintf("%s/%d/day/%d", c.BaseURL, year, day)
+}
+
+// Statement downloads the HTML page of the puzzle of year/day.
+func (c *Client) Statement(ctx context.Context, year, day int) (string, error) {
+	page, err := c.get(ctx, c.StatementURL(year, day))
+	if err != nil {
+		return "", err
+	}
+	return string(page), nil
+}
+
+// Input downloads the puzzle input of year/day for the logged in user.
+func (c *Client) Input(ctx context.Context, year, day int) ([]byte, error) {
+	if c.Session == "" {
+		return nil, ErrNoSession
+	}
+	return c.get(ctx, c.StatementURL(year, day)+"/input")
+}
+
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "aoc fetch")
+	if c.Session != "" {
+		req.AddCookie(&http.Cookie{Name: "session", Value: c.Session})
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching %s: %s", url, resp.Status)
+	}
+	return body, nil
+}
@@ -0,0 +1,119 @@
+package puzzle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+)
+
+const session = "s3cr3t"
+
+// newStub serves the statement page in testdata as the puzzle of 1999/1, and
+// an input to the holder of the session cookie.
+func newStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	page, err := os.ReadFile("testdata/statement.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /1999/day/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(page)
+	})
+	mux.HandleFunc("GET /1999/day/1/input", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != session {
+			http.Error(w, "Puzzle inputs differ by user.  Please log in to get your puzzle input.", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("1   2\n3   4\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient(t *testing.T) {
+	server := newStub(t)
+	ctx := context.Background()
+
+	client := NewClient(server.URL+"/", session)
+	if got, want := client.StatementURL(1999, 1), server.URL+"/1999/day/1"; got != want {
+		t.Errorf("got statement URL %s, want %s", got, want)
+	}
+	if _, err := client.Statement(ctx, 1999, 1); err != nil {
+		t.Errorf("statement: %v", err)
+	}
+	if input, err := client.Input(ctx, 1999, 1); err != nil || string(input) != "1   2\n3   4\n" {
+		t.Errorf("input: got %q, %v", input, err)
+	}
+	if _, err := client.Statement(ctx, 1999, 2); err == nil {
+		t.Error("statement of a missing day: got no error")
+	}
+
+	if _, err := NewClient(server.URL, "").Input(ctx, 1999, 1); !errors.Is(err, ErrNoSession) {
+		t.Errorf("input without a session: got %v, want %v", err, ErrNoSession)
+	}
+	if _, err := NewClient(server.URL, "stale").Input(ctx, 1999, 1); err == nil {
+		t.Error("input with a wrong session: got no error")
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	server := newStub(t)
+	client := NewClient(server.URL, session)
+	page, err := client.Statement(context.Background(), 1999, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "## Day 1: Counting Stars\n" +
+		"\n" +
+		"The elves count the *stars* in the sky, one `*` at a time & write them to a [list](" + server.URL + "/1999/about).\n" +
+		"\n" +
+		"For example:\n" +
+		"\n" +
+		"```\n" +
+		"1   2\n" +
+		"3   4\n" +
+		"```\n" +
+		"\n" +
+		"Some notes:\n" +
+		"\n" +
+		"- Every line holds *two* numbers.\n" +
+		"- Lines end in `\\n`.\n" +
+		"\n" +
+		"What is the *sum* of the numbers?\n" +
+		"\n" +
+		"## Part Two\n" +
+		"\n" +
+		"Now multiply them:\n" +
+		"\n" +
+		"```\n" +
+		"5 < 6\n" +
+		"```\n"
+	if got := Markdown(page, client.StatementURL(1999, 1)); got != want {
+		t.Errorf("got Markdown\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Markdown("<html><body><p>Please log in.</p></body></html>", server.URL); got != "" {
+		t.Errorf("page without articles: got Markdown %q, want none", got)
+	}
+}
+
+func TestExamples(t *testing.T) {
+	page, err := os.ReadFile("testdata/statement.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"1   2\n3   4\n", "5 < 6\n"}
+	if got := Examples(string(page)); !slices.Equal(got, want) {
+		t.Errorf("got examples %q, want %q", got, want)
+	}
+}
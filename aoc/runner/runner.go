@@ -0,0 +1,400 @@
+// Package runner locates, builds and runs the solutions of individual days.
+//
+// Every day is its own Go module living in <root>/<year>/day/<day> and reading
+// its puzzle input from input.txt in the working directory, so the runner
+// builds the day once and executes the binary in a directory holding the
+// requested input.
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Day identifies a single puzzle solution on disk.
+type Day struct {
+	Year int    `json:"year"`
+	Day  int    `json:"day"`
+	Dir  string `json:"-"`
+}
+
+func (d Day) String() string {
+	return fmt.Sprintf("%d/%d", d.Year, d.Day)
+}
+
+// Visualizable reports whether the day records its states with the visual
+// package and so accepts a -visualize flag.
+func (d Day) Visualizable() bool {
+	return d.imports("aoc/visual")
+}
+
+// Traceable reports whether the day emits its steps with the trace package
+// and so accepts a -trace-steps flag.
+func (d Day) Traceable() bool {
+	return d.imports("aoc/trace")
+}
+
+// Streamable reports whether the day has streaming solvers for inputs too
+// large for memory and so accepts a -stream flag.
+func (d Day) Streamable() bool {
+	return d.imports("aoc/stream")
+}
+
+func (d Day) imports(pkg string) bool {
+	sources, _ := filepath.Glob(filepath.Join(d.Dir, "*.go"))
+	for _, source := range sources {
+		data, err := os.ReadFile(source)
+		if err == nil && strings.Contains(string(data), `"`+pkg+`"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultInput is the input file every day reads.
+const DefaultInput = "input.txt"
+
+// FindRoot walks up from start to the repository root, recognised by the aoc
+// module living directly inside it.
+func FindRoot(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "aoc", "go.mod")); err == nil {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no repository root above %s", start)
+		}
+		dir = parent
+	}
+}
+
+// Find returns the given day, failing if it has no solution in root.
+func Find(root string, year, day int) (Day, error) {
+	dir := filepath.Join(root, strconv.Itoa(year), "day", strconv.Itoa(day))
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+		return Day{}, fmt.Errorf("no solution for %d/%d: %w", year, day, err)
+	}
+
+	return Day{Year: year, Day: day, Dir: dir}, nil
+}
+
+// List returns every day with a solution in root, ordered by year and day.
+func List(root string) ([]Day, error) {
+	modules, err := filepath.Glob(filepath.Join(root, "*", "day", "*", "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	days := make([]Day, 0, len(modules))
+	for _, module := range modules {
+		dir := filepath.Dir(module)
+		day, dayErr := strconv.Atoi(filepath.Base(dir))
+		year, yearErr := strconv.Atoi(filepath.Base(filepath.Dir(filepath.Dir(dir))))
+		if dayErr != nil || yearErr != nil {
+			continue
+		}
+		days = append(days, Day{Year: year, Day: day, Dir: dir})
+	}
+
+	slices.SortFunc(days, func(a, b Day) int {
+		if a.Year != b.Year {
+			return a.Year - b.Year
+		}
+		return a.Day - b.Day
+	})
+
+	return days, nil
+}
+
+// Build compiles the day into a binary in the user cache directory and returns
+// its path. The go build cache keeps repeated builds cheap.
+func Build(ctx context.Context, day Day) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	binDir := filepath.Join(cacheDir, "aoc", "bin")
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating binary directory: %w", err)
+	}
+
+	// other commands may be building or running the same day, so the binary
+	// is built aside and replaces the previous one in a single rename
+	buildDir, err := os.MkdirTemp(binDir, "build-")
+	if err != nil {
+		return "", fmt.Errorf("error creating build directory: %w", err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	name := fmt.Sprintf("%d-%d", day.Year, day.Day)
+	if err := BuildTo(ctx, day, filepath.Join(buildDir, name)); err != nil {
+		return "", err
+	}
+	binary := filepath.Join(binDir, name)
+	if err := os.Rename(filepath.Join(buildDir, name), binary); err != nil {
+		return "", fmt.Errorf("error replacing %s: %w", binary, err)
+	}
+
+	return binary, nil
+}
+
+// BuildTo compiles the day into binary.
+func BuildTo(ctx context.Context, day Day, binary string) error {
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", binary, ".")
+	cmd.Dir = day.Dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error building %v: %w\n%s", day, err, output)
+	}
+
+	return nil
+}
+
+// Test runs the go tests of the day and returns their output.
+func Test(ctx context.Context, day Day) (string, error) {
+	cmd := exec.CommandContext(ctx, "go", "test", ".")
+	cmd.Dir = day.Dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("error testing %v: %w", day, err)
+	}
+
+	return string(output), nil
+}
+
+// Options configure a single run of a day.
+type Options struct {
+	// Input is the path of the puzzle input, relative to the day directory
+	// unless absolute. Empty means DefaultInput.
+	Input string
+	// InputData, when not nil, is used as the puzzle input instead of Input.
+	InputData []byte
+	// Params are passed to the day as -param key=value, in order.
+	Params []string
+	// Args are extra command-line arguments passed to the day.
+	Args []string
+	// Timeout limits the run, zero means no limit.
+	Timeout time.Duration
+}
+
+// Part is a single answer printed by a day.
+type Part struct {
+	Name   string `json:"name"`
+	Label  string `json:"label"`
+	Answer string `json:"answer"`
+}
+
+// Result is the outcome of running a day.
+type Result struct {
+	Day      Day           `json:"day"`
+	Parts    []Part        `json:"parts"`
+	Output   string        `json:"output"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Answer returns the answer of the named part ("one" or "two").
+func (r Result) Answer(name string) (string, bool) {
+	for _, part := range r.Parts {
+		if part.Name == name {
+			return part.Answer, true
+		}
+	}
+	return "", false
+}
+
+var ErrTimeout = errors.New("timed out")
+
+// Run builds the day and runs it with the given options.
+func Run(ctx context.Context, day Day, options Options) (Result, error) {
+	binary, err := Build(ctx, day)
+	if err != nil {
+		return Result{Day: day}, err
+	}
+
+	return Exec(ctx, day, binary, options)
+}
+
+// Exec runs an already built binary of the day.
+func Exec(ctx context.Context, day Day, binary string, options Options) (Result, error) {
+	result := Result{Day: day}
+
+	workDir, cleanup, err := prepareInput(day, options)
+	if err != nil {
+		return result, err
+	}
+	defer cleanup()
+
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	args := make([]string, 0, len(options.Args)+2*len(options.Params))
+	for _, param := range options.Params {
+		args = append(args, "-param", param)
+	}
+	args = append(args, options.Args...)
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = workDir
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	start := time.Now()
+	err = cmd.Run()
+	result.Duration = time.Since(start)
+	result.Output = output.String()
+	result.Parts = ParseOutput(result.Output)
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return result, fmt.Errorf("%v %w after %v", day, ErrTimeout, options.Timeout)
+	}
+	if err != nil {
+		return result, fmt.Errorf("error running %v: %w", day, err)
+	}
+	if len(result.Parts) == 0 {
+		return result, fmt.Errorf("%v printed no answers: %s", day, strings.TrimSpace(result.Output))
+	}
+
+	return result, nil
+}
+
+// prepareInput returns the directory the day has to run in so that its
+// input.txt is the requested input.
+func prepareInput(day Day, options Options) (dir string, cleanup func(), err error) {
+	noop := func() {}
+	if options.InputData != nil {
+		return WriteInput(options.InputData)
+	}
+	if options.Input == "" {
+		return day.Dir, noop, nil
+	}
+
+	path := options.Input
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(day.Dir, path)
+	}
+	if filepath.Clean(path) == filepath.Join(day.Dir, DefaultInput) {
+		return day.Dir, noop, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", noop, fmt.Errorf("error reading input: %w", err)
+	}
+
+	return WriteInput(data)
+}
+
+// WriteInput creates a temporary working directory whose input.txt holds data.
+func WriteInput(data []byte) (dir string, cleanup func(), err error) {
+	dir, err = os.MkdirTemp("", "aoc-input-")
+	if err != nil {
+		return "", func() {}, fmt.Errorf("error creating input directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	if err := os.WriteFile(filepath.Join(dir, DefaultInput), data, 0o644); err != nil {
+		cleanup()
+		return "", func() {}, fmt.Errorf("error writing input: %w", err)
+	}
+
+	return dir, cleanup, nil
+}
+
+var (
+	partRe   = regexp.MustCompile(`(?m)^\(Part (\w+)\) (.*)$`)
+	numberRe = regexp.MustCompile(`-?\d+`)
+)
+
+// ParseOutput extracts the answers from the "(Part one) label: answer" lines
+// every day prints. Lines without a colon, like "(Part one) XMAS appears 18
+// times", are answered by their last number. Output that is a single JSON
+// document instead, like day 1's -report json, is answered by the part_one and
+// part_two of its answers object.
+func ParseOutput(output string) []Part {
+	parts := make([]Part, 0, 2)
+	for _, match := range partRe.FindAllStringSubmatch(output, -1) {
+		part := Part{Name: strings.ToLower(match[1])}
+		text := strings.TrimSpace(match[2])
+		if i := strings.LastIndex(text, ": "); i != -1 {
+			part.Label, part.Answer = text[:i], strings.TrimSpace(text[i+2:])
+		} else if numbers := numberRe.FindAllString(text, -1); len(numbers) > 0 {
+			part.Label, part.Answer = text, numbers[len(numbers)-1]
+		} else {
+			part.Label = text
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return parseJSONAnswers(output)
+	}
+	return parts
+}
+
+// parseJSONAnswers reads the answers of a JSON document, if output is one.
+func parseJSONAnswers(output string) []Part {
+	parts := make([]Part, 0, 2)
+	var document struct {
+		Answers map[string]json.RawMessage `json:"answers"`
+	}
+	if err := json.Unmarshal([]byte(output), &document); err != nil {
+		return parts
+	}
+	for _, name := range []string{"one", "two"} {
+		answer, ok := document.Answers["part_"+name]
+		if !ok {
+			continue
+		}
+		parts = append(parts, Part{Name: name, Label: "part_" + name, Answer: strings.Trim(string(answer), `"`)})
+	}
+	return parts
+}
+
+// Visualize runs the day with -visualize and returns the visualization JSON it
+// wrote, see package visual.
+func Visualize(ctx context.Context, day Day, binary string, options Options) ([]byte, Result, error) {
+	if !day.Visualizable() {
+		return nil, Result{Day: day}, fmt.Errorf("%v has no visualization", day)
+	}
+
+	file, err := os.CreateTemp("", "aoc-visualization-*.json")
+	if err != nil {
+		return nil, Result{Day: day}, err
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	options.Args = append(slices.Clip(options.Args), "-visualize", file.Name())
+	result, err := Exec(ctx, day, binary, options)
+	if err != nil {
+		return nil, result, err
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil || len(data) == 0 {
+		return nil, result, fmt.Errorf("%v wrote no visualization", day)
+	}
+
+	return data, result, nil
+}
@@ -0,0 +1,33 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []Part
+	}{
+		{"lines", "(Part one) sum of distances: 11\nsomething else\n(Part two) similarity: 31\n", []Part{
+			{Name: "one", Label: "sum of distances", Answer: "11"},
+			{Name: "two", Label: "similarity", Answer: "31"},
+		}},
+		{"last number", "(Part one) XMAS appears 18 times\n", []Part{
+			{Name: "one", Label: "XMAS appears 18 times", Answer: "18"},
+		}},
+		{"json", `{"answers": {"part_one": 11, "part_two": "31"}, "lists": []}` + "\n", []Part{
+			{Name: "one", Label: "part_one", Answer: "11"},
+			{Name: "two", Label: "part_two", Answer: "31"},
+		}},
+		{"json without answers", `{"lists": []}`, []Part{}},
+		{"no answers", "error reading input: no such file", []Part{}},
+	}
+	for _, test := range tests {
+		if got := ParseOutput(test.output); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
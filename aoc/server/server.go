@@ -0,0 +1,285 @@
+// Package server exposes the daily solutions over a local HTTP/JSON API.
+//
+//	GET  /                          web UI for the visualizations
+//	GET  /days                      list days and their parameters
+//	POST /{year}/{day}              run a day on the request body, both parts
+//	POST /{year}/{day}/{part}       run a day on the request body, one part
+//	GET  /visualize/{year}/{day}    step-by-step states of a grid day on its input
+//	POST /visualize/{year}/{day}    step-by-step states of a grid day on the body
+//
+// Query values on POST requests are passed to the day as parameters, so
+// POST /2024/14/1?width=11&height=7 runs day 14 on an example.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"aoc/config"
+	"aoc/params"
+	"aoc/runner"
+)
+
+// Options limit what a single request may do.
+type Options struct {
+	// MaxInputSize is the largest accepted request body in bytes.
+	MaxInputSize int64
+	// Timeout limits a single run of a day.
+	Timeout time.Duration
+}
+
+type Server struct {
+	root    string
+	config  config.Config
+	options Options
+	mux     *http.ServeMux
+
+	mu     sync.Mutex
+	builds map[runner.Day]*dayBuild
+}
+
+// build is a day binary and its declared parameters, or why it didn't build.
+type build struct {
+	binary string
+	params []params.Param
+	err    error
+}
+
+// dayBuild keeps the build of a day once it succeeded. A failed build is not
+// kept, so that the next request tries again, e.g. once the day is fixed.
+type dayBuild struct {
+	mu    sync.Mutex
+	built *build
+}
+
+func New(root string, cfg config.Config, options Options) *Server {
+	s := &Server{
+		root:    root,
+		config:  cfg,
+		options: options,
+		mux:     http.NewServeMux(),
+		builds:  make(map[runner.Day]*dayBuild),
+	}
+
+	s.mux.Handle("GET /{$}", http.FileServerFS(ui))
+	s.mux.HandleFunc("GET /days", s.handleList)
+	s.mux.HandleFunc("GET /visualize/{year}/{day}", s.handleVisualize)
+	s.mux.HandleFunc("POST /visualize/{year}/{day}", s.handleVisualize)
+	s.mux.HandleFunc("POST /{year}/{day}", s.handleRun)
+	s.mux.HandleFunc("POST /{year}/{day}/{part}", s.handleRun)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+type dayInfo struct {
+	Year   int            `json:"year"`
+	Day    int            `json:"day"`
+	Params []params.Param `json:"params"`
+	// Visual reports whether the day can be visualized.
+	Visual bool   `json:"visual"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	days, err := runner.List(s.root)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	infos := make([]dayInfo, 0, len(days))
+	for _, day := range days {
+		info := dayInfo{Year: day.Year, Day: day.Day, Params: []params.Param{}, Visual: day.Visualizable()}
+		b := s.build(r.Context(), day)
+		if b.err != nil {
+			info.Error = b.err.Error()
+		} else {
+			info.Params = b.params
+		}
+		infos = append(infos, info)
+	}
+
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// RunResponse is the body returned for a run request.
+type RunResponse struct {
+	Year     int           `json:"year"`
+	Day      int           `json:"day"`
+	Parts    []runner.Part `json:"parts"`
+	Duration time.Duration `json:"duration_ns"`
+	Output   string        `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	day, err := s.pathDay(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	partName := ""
+	if part := r.PathValue("part"); part != "" {
+		names := map[string]string{"1": "one", "2": "two"}
+		if partName = names[part]; partName == "" {
+			writeError(w, http.StatusNotFound, fmt.Errorf("invalid part %q, expected 1 or 2", part))
+			return
+		}
+	}
+
+	input, status, err := s.readInput(w, r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+	if len(input) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("empty input"))
+		return
+	}
+
+	b := s.build(r.Context(), day)
+	if b.err != nil {
+		writeError(w, http.StatusInternalServerError, b.err)
+		return
+	}
+
+	result, err := runner.Exec(r.Context(), day, b.binary, runner.Options{
+		InputData: input,
+		Params:    s.requestParams(day, r),
+		Timeout:   s.options.Timeout,
+	})
+	response := RunResponse{Year: day.Year, Day: day.Day, Parts: result.Parts, Duration: result.Duration}
+	if partName != "" {
+		response.Parts = slices.DeleteFunc(response.Parts, func(p runner.Part) bool { return p.Name != partName })
+	}
+
+	status = http.StatusOK
+	switch {
+	case errors.Is(err, runner.ErrTimeout):
+		status = http.StatusGatewayTimeout
+	case err != nil:
+		status = http.StatusUnprocessableEntity
+	case len(response.Parts) == 0:
+		status = http.StatusUnprocessableEntity
+		err = fmt.Errorf("%v printed no answer for part %s", day, partName)
+	}
+	if err != nil {
+		response.Error = err.Error()
+		response.Output = result.Output
+	}
+
+	writeJSON(w, status, response)
+}
+
+// pathDay returns the day named by the {year} and {day} path values.
+func (s *Server) pathDay(r *http.Request) (runner.Day, error) {
+	year, yearErr := strconv.Atoi(r.PathValue("year"))
+	day, dayErr := strconv.Atoi(r.PathValue("day"))
+	if yearErr != nil || dayErr != nil {
+		return runner.Day{}, fmt.Errorf("invalid day %s/%s", r.PathValue("year"), r.PathValue("day"))
+	}
+
+	return runner.Find(s.root, year, day)
+}
+
+// readInput reads the request body up to the size limit, returning the HTTP
+// status to answer with on failure.
+func (s *Server) readInput(w http.ResponseWriter, r *http.Request) ([]byte, int, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.options.MaxInputSize)
+	input, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("input larger than %d bytes", tooLarge.Limit)
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	return input, http.StatusOK, nil
+}
+
+// requestParams returns the configured parameters of the day followed by the
+// ones given as query values.
+func (s *Server) requestParams(day runner.Day, r *http.Request) []string {
+	dayParams := s.config.Params(day.Year, day.Day, "")
+	query := r.URL.Query()
+	for _, key := range slices.Sorted(maps.Keys(query)) {
+		for _, value := range query[key] {
+			dayParams = append(dayParams, key+"="+value)
+		}
+	}
+	return dayParams
+}
+
+// build returns the day's binary and declared parameters, building it on
+// first use and after a failed build.
+func (s *Server) build(ctx context.Context, day runner.Day) build {
+	s.mu.Lock()
+	d, ok := s.builds[day]
+	if !ok {
+		d = &dayBuild{}
+		s.builds[day] = d
+	}
+	s.mu.Unlock()
+
+	// other requests for the day wait for this build
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.built != nil {
+		return *d.built
+	}
+
+	// not bound to the request, as its result is kept for good
+	ctx = context.WithoutCancel(ctx)
+	binary, err := runner.Build(ctx, day)
+	if err != nil {
+		return build{err: err}
+	}
+	d.built = &build{binary: binary, params: listParams(ctx, binary)}
+	return *d.built
+}
+
+// listParamsTimeout bounds listParams, which runs detached from any request.
+const listParamsTimeout = 10 * time.Second
+
+// listParams asks a day binary for its parameters. Days that don't declare any
+// don't know the -list-params flag, which is fine.
+func listParams(ctx context.Context, binary string) []params.Param {
+	ctx, cancel := context.WithTimeout(ctx, listParamsTimeout)
+	defer cancel()
+
+	declared := []params.Param{}
+	output, err := exec.CommandContext(ctx, binary, "-list-params").Output()
+	if err != nil {
+		return declared
+	}
+	if err := json.Unmarshal(output, &declared); err != nil {
+		return []params.Param{}
+	}
+	return declared
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,255 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aoc/config"
+	"aoc/runner"
+)
+
+// sumDay adds up the numbers of its input in part one and multiplies the sum
+// by its factor parameter in part two.
+const sumDay = `package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"aoc/params"
+)
+
+var factor = params.Int("factor", 1, "what part two multiplies the sum by")
+
+func main() {
+	if err := params.Parse(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	input, err := os.ReadFile("input.txt")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	sum := 0
+	for _, field := range strings.Fields(string(input)) {
+		n, _ := strconv.Atoi(field)
+		sum += n
+	}
+	fmt.Printf("(Part one) sum: %d\n", sum)
+	fmt.Printf("(Part two) product: %d\n", sum**factor)
+}
+`
+
+// slowDay never answers in time.
+const slowDay = `package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+func main() {
+	flag.Parse()
+	time.Sleep(time.Minute)
+	fmt.Println("(Part one) late: 1")
+}
+`
+
+// newTestServer returns a server over a root holding the fixture days 1999/1
+// and 1999/2, next to this aoc module, building into a temporary cache.
+func newTestServer(t *testing.T, cfg config.Config, options Options) *Server {
+	t.Helper()
+	// the binaries go to a temporary cache, but the go build cache stays so
+	// that the fixtures don't rebuild the standard library
+	goCache, err := exec.Command("go", "env", "GOCACHE").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOCACHE", strings.TrimSpace(string(goCache)))
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	root := t.TempDir()
+	aoc, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(aoc, filepath.Join(root, "aoc")); err != nil {
+		t.Fatal(err)
+	}
+	for day, source := range map[string]string{"1": sumDay, "2": slowDay} {
+		dir := filepath.Join(root, "1999", "day", day)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		mod := "module fixture\n\ngo 1.23.3\n\nrequire aoc v0.0.0\n\nreplace aoc => ../../../aoc\n"
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if cfg.Days == nil {
+		cfg.Days = map[string]config.Day{}
+	}
+	return New(root, cfg, options)
+}
+
+func post(t *testing.T, s *Server, target, body string) (int, RunResponse) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+
+	var response RunResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("POST %s: error decoding response: %v", target, err)
+	}
+	return recorder.Code, response
+}
+
+func answers(response RunResponse) map[string]string {
+	answers := make(map[string]string)
+	for _, part := range response.Parts {
+		answers[part.Name] = part.Answer
+	}
+	return answers
+}
+
+func TestRun(t *testing.T) {
+	cfg := config.Config{Days: map[string]config.Day{
+		"1999/1": {Params: map[string]config.Value{"factor": "2"}},
+	}}
+	s := newTestServer(t, cfg, Options{MaxInputSize: 16, Timeout: time.Minute})
+
+	tests := []struct {
+		target string
+		body   string
+		status int
+		want   map[string]string
+	}{
+		{"/1999/1", "1 2 3", http.StatusOK, map[string]string{"one": "6", "two": "12"}},
+		{"/1999/1/1", "1 2 3", http.StatusOK, map[string]string{"one": "6"}},
+		{"/1999/1/2", "1 2 3", http.StatusOK, map[string]string{"two": "12"}},
+		// query values come after the configured parameters and win
+		{"/1999/1/2?factor=10", "1 2 3", http.StatusOK, map[string]string{"two": "60"}},
+		{"/1999/1?factor=x", "1 2 3", http.StatusUnprocessableEntity, map[string]string{}},
+		{"/1999/1?unknown=1", "1 2 3", http.StatusUnprocessableEntity, map[string]string{}},
+		{"/1999/1/3", "1 2 3", http.StatusNotFound, map[string]string{}},
+		{"/1999/7", "1 2 3", http.StatusNotFound, map[string]string{}},
+		{"/1999/1", "", http.StatusBadRequest, map[string]string{}},
+		{"/1999/1", "1 2 3 4 5 6 7 8 9", http.StatusRequestEntityTooLarge, map[string]string{}},
+	}
+
+	for _, test := range tests {
+		status, response := post(t, s, test.target, test.body)
+		if status != test.status {
+			t.Errorf("POST %s: got status %d, want %d: %+v", test.target, status, test.status, response)
+			continue
+		}
+		if got := answers(response); len(got) != len(test.want) || !mapsEqual(got, test.want) {
+			t.Errorf("POST %s: got answers %v, want %v", test.target, got, test.want)
+		}
+		if status != http.StatusOK && response.Error == "" {
+			t.Errorf("POST %s: got no error with status %d", test.target, status)
+		}
+	}
+}
+
+func mapsEqual(a, b map[string]string) bool {
+	for key, value := range a {
+		if b[key] != value {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+func TestRunTimeout(t *testing.T) {
+	s := newTestServer(t, config.Config{}, Options{MaxInputSize: 16, Timeout: 200 * time.Millisecond})
+
+	status, response := post(t, s, "/1999/2", "1")
+	if status != http.StatusGatewayTimeout {
+		t.Fatalf("got status %d, want %d: %+v", status, http.StatusGatewayTimeout, response)
+	}
+	if !strings.Contains(response.Error, "timed out") {
+		t.Errorf("got error %q, want a timeout", response.Error)
+	}
+}
+
+func TestList(t *testing.T) {
+	s := newTestServer(t, config.Config{}, Options{MaxInputSize: 16, Timeout: time.Minute})
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/days", nil))
+	var infos []dayInfo
+	if err := json.NewDecoder(recorder.Body).Decode(&infos); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(infos) != 2 {
+		t.Fatalf("got %d days, want 2: %+v", len(infos), infos)
+	}
+	if params := infos[0].Params; len(params) != 1 || params[0].Name != "factor" || params[0].Default != "1" {
+		t.Errorf("1999/1: got params %+v, want factor defaulting to 1", params)
+	}
+	if params := infos[1].Params; len(params) != 0 {
+		t.Errorf("1999/2: got params %+v, want none", params)
+	}
+}
+
+// TestBuildCancelled checks that a first request giving up does not leave its
+// day without parameters for the requests after it.
+func TestBuildCancelled(t *testing.T) {
+	s := newTestServer(t, config.Config{}, Options{MaxInputSize: 16, Timeout: time.Minute})
+	day, err := runner.Find(s.root, 1999, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b := s.build(ctx, day)
+	if b.err != nil {
+		t.Fatal(b.err)
+	}
+	if len(b.params) != 1 || b.params[0].Name != "factor" {
+		t.Errorf("got params %+v, want factor", b.params)
+	}
+}
+
+// TestBuildRetried checks that a failed build is not kept, so that fixing the
+// day is enough for the next request to run it.
+func TestBuildRetried(t *testing.T) {
+	s := newTestServer(t, config.Config{}, Options{MaxInputSize: 16, Timeout: time.Minute})
+	day, err := runner.Find(s.root, 1999, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := filepath.Join(day.Dir, "main.go")
+	if err := os.WriteFile(source, []byte("package main\n\nfunc main() { undefined() }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if b := s.build(context.Background(), day); b.err == nil {
+		t.Fatal("broken day: got no build error")
+	}
+
+	if err := os.WriteFile(source, []byte(sumDay), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code, response := post(t, s, "/1999/1?factor=2", "1 2 3"); code != http.StatusOK || answers(response)["two"] != "12" {
+		t.Errorf("fixed day: got %d %+v, want 200 with 12", code, response)
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+
+	"aoc/runner"
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+var ui, _ = fs.Sub(uiFiles, "ui")
+
+func (s *Server) handleVisualize(w http.ResponseWriter, r *http.Request) {
+	day, err := s.pathDay(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if !day.Visualizable() {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%v has no visualization", day))
+		return
+	}
+
+	input, status, err := s.readInput(w, r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	b := s.build(r.Context(), day)
+	if b.err != nil {
+		writeError(w, http.StatusInternalServerError, b.err)
+		return
+	}
+
+	options := runner.Options{
+		Params:  s.requestParams(day, r),
+		Timeout: s.options.Timeout,
+	}
+	if len(input) > 0 {
+		options.InputData = input
+	}
+
+	data, result, err := runner.Visualize(r.Context(), day, b.binary, options)
+	if errors.Is(err, runner.ErrTimeout) {
+		writeError(w, http.StatusGatewayTimeout, err)
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, RunResponse{Year: day.Year, Day: day.Day, Output: result.Output, Error: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
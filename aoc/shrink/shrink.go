@@ -0,0 +1,295 @@
+// Package shrink reduces a puzzle input to a minimal one that still shows a
+// failure, using delta debugging over units of the input's structure: lines,
+// grid rows and columns, blank-line separated blocks and so on. Reducers keep
+// the input well-formed so that a reduced input fails for the same reason
+// rather than because it no longer parses.
+package shrink
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Reducer splits an input into units that can be dropped independently. join
+// rebuilds a well-formed input from any non-empty subset of the units, in
+// their original order.
+type Reducer struct {
+	Name  string
+	Split func(input string) (units []string, join func(units []string) string)
+}
+
+// Test reports whether a candidate input still shows the failure.
+type Test func(ctx context.Context, input string) (bool, error)
+
+// Stats describes a finished reduction.
+type Stats struct {
+	// Tests is the number of candidates run, cached repeats excluded.
+	Tests int
+}
+
+// Minimize applies the reducers in turn until none of them can drop anything
+// more and returns the smallest failing input found. The input itself must
+// fail.
+func Minimize(ctx context.Context, input string, reducers []Reducer, test Test) (string, Stats, error) {
+	m := &minimizer{test: test, seen: map[string]bool{}}
+
+	ok, err := m.check(ctx, input)
+	if err != nil {
+		return input, m.stats, err
+	}
+	if !ok {
+		return input, m.stats, fmt.Errorf("the input does not fail, nothing to shrink")
+	}
+
+	for progress := true; progress; {
+		progress = false
+		for _, reducer := range reducers {
+			units, join := reducer.Split(input)
+			if len(units) < 2 {
+				continue
+			}
+
+			reduced, err := m.ddmin(ctx, units, join)
+			if err != nil {
+				return input, m.stats, err
+			}
+			if len(reduced) < len(units) {
+				input = join(reduced)
+				progress = true
+			}
+		}
+	}
+
+	return input, m.stats, nil
+}
+
+type minimizer struct {
+	test  Test
+	seen  map[string]bool
+	stats Stats
+}
+
+func (m *minimizer) check(ctx context.Context, input string) (bool, error) {
+	if ok, seen := m.seen[input]; seen {
+		return ok, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	ok, err := m.test(ctx, input)
+	if err != nil {
+		return false, err
+	}
+	m.seen[input] = ok
+	m.stats.Tests++
+	return ok, nil
+}
+
+// ddmin is Zeller's minimizing delta debugging: split the units into n chunks,
+// keep a single chunk or drop one if that still fails, and refine the
+// granularity when neither does. The result is 1-minimal: dropping any single
+// unit makes the failure go away.
+func (m *minimizer) ddmin(ctx context.Context, units []string, join func([]string) string) ([]string, error) {
+	n := 2
+	for len(units) >= 2 {
+		size := (len(units) + n - 1) / n
+		reduced := false
+
+		for start := 0; start < len(units) && !reduced; start += size {
+			end := min(start+size, len(units))
+
+			subset := units[start:end]
+			ok, err := m.check(ctx, join(subset))
+			if err != nil {
+				return units, err
+			}
+			if ok {
+				units, n, reduced = slices.Clone(subset), 2, true
+				break
+			}
+
+			complement := slices.Concat(units[:start], units[end:])
+			if len(complement) == 0 {
+				continue
+			}
+			ok, err = m.check(ctx, join(complement))
+			if err != nil {
+				return units, err
+			}
+			if ok {
+				units, n, reduced = complement, max(n-1, 2), true
+			}
+		}
+
+		if !reduced {
+			if n >= len(units) {
+				break
+			}
+			n = min(2*n, len(units))
+		}
+	}
+
+	return units, nil
+}
+
+// splitLines splits input into lines, join restores a trailing newline if the
+// input had one.
+func splitLines(input string) ([]string, func(lines []string) string) {
+	trimmed, newline := strings.CutSuffix(input, "\n")
+	suffix := ""
+	if newline {
+		suffix = "\n"
+	}
+	return strings.Split(trimmed, "\n"), func(lines []string) string {
+		return strings.Join(lines, "\n") + suffix
+	}
+}
+
+// Lines drops whole lines, or the rows of a character grid.
+var Lines = Reducer{Name: "lines", Split: splitLines}
+
+// GridColumns drops columns of a character grid, keeping it rectangular.
+var GridColumns = Reducer{Name: "grid columns", Split: func(input string) ([]string, func([]string) string) {
+	rows, joinRows := splitLines(input)
+	grid := make([][]rune, len(rows))
+	for i, row := range rows {
+		grid[i] = []rune(row)
+		if len(grid[i]) != len(grid[0]) {
+			// not a grid, leave it to the other reducers
+			return nil, joinRows
+		}
+	}
+
+	columns := make([]string, len(grid[0]))
+	for x := range columns {
+		column := make([]rune, len(grid))
+		for y := range grid {
+			column[y] = grid[y][x]
+		}
+		columns[x] = string(column)
+	}
+
+	return columns, func(columns []string) string {
+		transposed := make([][]rune, len(rows))
+		for _, column := range columns {
+			for y, r := range []rune(column) {
+				transposed[y] = append(transposed[y], r)
+			}
+		}
+		rows := make([]string, len(transposed))
+		for y, row := range transposed {
+			rows[y] = string(row)
+		}
+		return joinRows(rows)
+	}
+}}
+
+// Blocks drops blocks separated by blank lines.
+var Blocks = Reducer{Name: "blocks", Split: func(input string) ([]string, func([]string) string) {
+	trimmed := strings.TrimRight(input, "\n")
+	suffix := input[len(trimmed):]
+	return strings.Split(trimmed, "\n\n"), func(blocks []string) string {
+		return strings.Join(blocks, "\n\n") + suffix
+	}
+}}
+
+// SectionLines drops lines within the section-th blank-line separated block,
+// leaving the other blocks as they are.
+func SectionLines(section int) Reducer {
+	return Reducer{Name: fmt.Sprintf("lines of section %d", section+1), Split: func(input string) ([]string, func([]string) string) {
+		blocks, joinBlocks := Blocks.Split(input)
+		if section >= len(blocks) {
+			return nil, joinBlocks
+		}
+
+		return strings.Split(blocks[section], "\n"), func(lines []string) string {
+			reduced := slices.Clone(blocks)
+			reduced[section] = strings.Join(lines, "\n")
+			return joinBlocks(reduced)
+		}
+	}}
+}
+
+// Fields drops whitespace separated fields of a single line input.
+var Fields = Reducer{Name: "fields", Split: func(input string) ([]string, func([]string) string) {
+	trimmed := strings.TrimRight(input, "\n")
+	suffix := input[len(trimmed):]
+	return strings.Fields(trimmed), func(fields []string) string {
+		return strings.Join(fields, " ") + suffix
+	}
+}}
+
+// Pairs drops pairs of characters of a single line input, such as a file and
+// the free space after it in a disk map.
+var Pairs = Reducer{Name: "pairs", Split: func(input string) ([]string, func([]string) string) {
+	trimmed := strings.TrimRight(input, "\n")
+	suffix := input[len(trimmed):]
+	runes := []rune(trimmed)
+	pairs := make([]string, 0, (len(runes)+1)/2)
+	for i := 0; i < len(runes); i += 2 {
+		pairs = append(pairs, string(runes[i:min(i+2, len(runes))]))
+	}
+	return pairs, func(pairs []string) string {
+		return strings.Join(pairs, "") + suffix
+	}
+}}
+
+// Chars drops single characters within lines.
+var Chars = Reducer{Name: "characters", Split: func(input string) ([]string, func([]string) string) {
+	lines, joinLines := splitLines(input)
+	// units are indexes into chars, which says where each goes back
+	type char struct {
+		line int
+		r    rune
+	}
+	var chars []char
+	units := make([]string, 0, len(input))
+	for i, line := range lines {
+		for _, r := range line {
+			units = append(units, strconv.Itoa(len(chars)))
+			chars = append(chars, char{line: i, r: r})
+		}
+	}
+	return units, func(units []string) string {
+		reduced := make([]strings.Builder, len(lines))
+		for _, unit := range units {
+			i, err := strconv.Atoi(unit)
+			if err != nil || i < 0 || i >= len(chars) {
+				panic(fmt.Sprintf("shrink: %q is not a unit of this input", unit))
+			}
+			reduced[chars[i].line].WriteRune(chars[i].r)
+		}
+		rows := make([]string, len(lines))
+		for i := range reduced {
+			rows[i] = reduced[i].String()
+		}
+		return joinLines(rows)
+	}
+}}
+
+// days holds the reducers matching the input format of each day, days not
+// listed are reduced by Lines.
+var days = map[string][]Reducer{
+	"2024/3":  {Lines, Chars},
+	"2024/4":  {Lines, GridColumns},
+	"2024/5":  {SectionLines(0), SectionLines(1)},
+	"2024/6":  {Lines, GridColumns},
+	"2024/8":  {Lines, GridColumns},
+	"2024/9":  {Pairs},
+	"2024/10": {Lines, GridColumns},
+	"2024/11": {Fields},
+	"2024/12": {Lines, GridColumns},
+	"2024/13": {Blocks},
+}
+
+// ForDay returns the reducers for the input format of year/day.
+func ForDay(year, day int) []Reducer {
+	if reducers, ok := days[fmt.Sprintf("%d/%d", year, day)]; ok {
+		return reducers
+	}
+	return []Reducer{Lines}
+}
@@ -0,0 +1,196 @@
+package shrink
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func contains(needles ...string) Test {
+	return func(ctx context.Context, input string) (bool, error) {
+		for _, needle := range needles {
+			if !strings.Contains(input, needle) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	var lines []string
+	for i := range 40 {
+		lines = append(lines, "line "+strconv.Itoa(i))
+	}
+	input := strings.Join(lines, "\n") + "\n"
+
+	got, stats, err := Minimize(context.Background(), input, []Reducer{Lines}, contains("line 3\n", "line 17\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "line 3\nline 17\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if stats.Tests == 0 || stats.Tests > 100 {
+		t.Errorf("got %d tests, want a few dozen", stats.Tests)
+	}
+
+	// the characters of a line, once the other lines are gone
+	got, _, err = Minimize(context.Background(), "xmul(2,4)%&\nmul[3,7]!do()_mul(5,5)\n", []Reducer{Lines, Chars}, contains("mul(5,5)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "mul(5,5)\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, _, err := Minimize(context.Background(), input, []Reducer{Lines}, contains("line 40\n")); err == nil {
+		t.Error("an input that does not fail: got no error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelling := func(ctx context.Context, input string) (bool, error) {
+		cancel()
+		return true, nil
+	}
+	if _, _, err := Minimize(ctx, input, []Reducer{Lines}, cancelling); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled: got %v, want %v", err, context.Canceled)
+	}
+}
+
+// TestDDMinOneMinimal checks that dropping any single unit of a reduction
+// makes the failure go away, for arbitrary failure conditions.
+func TestDDMinOneMinimal(t *testing.T) {
+	random := rand.New(rand.NewPCG(36, 42))
+	for round := range 200 {
+		units := make([]string, 2+random.IntN(30))
+		for i := range units {
+			units[i] = strconv.Itoa(i)
+		}
+		join := func(units []string) string { return strings.Join(units, ",") }
+		full := join(units)
+
+		// the full input and a third of the others fail, with no structure
+		seed := random.Uint64()
+		fails := func(input string) bool {
+			h := fnv.New64a()
+			h.Write([]byte(input))
+			return input == full || (h.Sum64()^seed)%3 == 0
+		}
+		test := func(ctx context.Context, input string) (bool, error) { return fails(input), nil }
+
+		m := &minimizer{test: test, seen: map[string]bool{}}
+		reduced, err := m.ddmin(context.Background(), units, join)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fails(join(reduced)) {
+			t.Fatalf("round %d: the reduction %v does not fail", round, reduced)
+		}
+		if len(reduced) < 2 {
+			continue
+		}
+		for i := range reduced {
+			if dropped := slices.Delete(slices.Clone(reduced), i, i+1); fails(join(dropped)) {
+				t.Errorf("round %d: %v still fails without %s", round, reduced, reduced[i])
+			}
+		}
+	}
+}
+
+// TestReducersKeepFormat joins random subsets of the units of every reducer
+// and checks the result is an input of the same format.
+func TestReducersKeepFormat(t *testing.T) {
+	tests := []struct {
+		reducer Reducer
+		input   string
+		// check returns what is wrong with joined, built from the kept units
+		check func(input, joined string, kept []int) string
+	}{
+		{Lines, "a\nbb\n\nccc\n", roundTrip(Lines)},
+		{Lines, "no newline\nat the end", roundTrip(Lines)},
+		{Blocks, "a\nb\n\nc\n\nd\ne\n\n", roundTrip(Blocks)},
+		{SectionLines(1), "47|53\n97|13\n\n75,47\n97,61,53\n29,13\n", roundTrip(SectionLines(1))},
+		{Fields, "125 17 0 9\n", roundTrip(Fields)},
+		{Pairs, "2333133121414131402\n", roundTrip(Pairs)},
+		{GridColumns, "MMMS\nMSAM\nAMXS\n", func(input, joined string, kept []int) string {
+			rows := strings.Split(strings.TrimSuffix(joined, "\n"), "\n")
+			for _, row := range rows {
+				if len(row) != len(kept) {
+					return "not a rectangle"
+				}
+			}
+			if len(rows) != 3 || !strings.HasSuffix(joined, "\n") {
+				return "lost rows"
+			}
+			return ""
+		}},
+		{Chars, "mul(1,2)\ndo()\n", func(input, joined string, kept []int) string {
+			var want []byte
+			position := 0
+			for _, c := range []byte(input) {
+				if c == '\n' {
+					want = append(want, c)
+					continue
+				}
+				if slices.Contains(kept, position) {
+					want = append(want, c)
+				}
+				position++
+			}
+			if joined != string(want) {
+				return "want " + strconv.Quote(string(want))
+			}
+			return ""
+		}},
+	}
+
+	random := rand.New(rand.NewPCG(36, 7))
+	for _, test := range tests {
+		units, join := test.reducer.Split(test.input)
+		if len(units) < 2 {
+			t.Fatalf("%s: got %d units of %q", test.reducer.Name, len(units), test.input)
+		}
+		if joined := join(units); joined != test.input {
+			t.Errorf("%s: joining all units gives %q, want %q", test.reducer.Name, joined, test.input)
+		}
+		for range 50 {
+			var kept []int
+			var subset []string
+			for i, unit := range units {
+				if random.IntN(2) == 0 {
+					kept = append(kept, i)
+					subset = append(subset, unit)
+				}
+			}
+			if len(subset) == 0 {
+				continue
+			}
+			joined := join(subset)
+			if problem := test.check(test.input, joined, kept); problem != "" {
+				t.Errorf("%s: units %v of %q joined to %q: %s", test.reducer.Name, kept, test.input, joined, problem)
+			}
+		}
+	}
+}
+
+// roundTrip checks that splitting a joined input gives back the kept units,
+// and so that it has the structure of the input.
+func roundTrip(r Reducer) func(input, joined string, kept []int) string {
+	return func(input, joined string, kept []int) string {
+		units, _ := r.Split(input)
+		want := make([]string, len(kept))
+		for i, k := range kept {
+			want[i] = units[k]
+		}
+		if got, _ := r.Split(joined); !slices.Equal(got, want) {
+			return "splits into " + strconv.Quote(strings.Join(got, "|"))
+		}
+		return ""
+	}
+}
@@ -0,0 +1,148 @@
+// Package stream helps solvers process inputs too large to load whole: a line
+// scanner that tolerates very long lines, a chunk reader for inputs that are
+// not made of lines and a windowed reader giving random access to a file in
+// constant memory.
+//
+// Days importing this package accept a -stream flag switching them to their
+// streaming solvers, see aoc run -stream.
+package stream
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// MaxLineLength is the longest line a Scanner accepts.
+const MaxLineLength = 64 * 1024 * 1024
+
+// Scanner reads an input line by line.
+type Scanner struct {
+	scanner *bufio.Scanner
+	line    int
+	err     error
+}
+
+func NewScanner(r io.Reader) *Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxLineLength)
+	return &Scanner{scanner: scanner}
+}
+
+// Lines yields the line number, starting at 1, and the text of every line.
+// Check Err once the loop is done.
+func (s *Scanner) Lines() iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for s.scanner.Scan() {
+			s.line++
+			if !yield(s.line, s.scanner.Text()) {
+				return
+			}
+		}
+		if err := s.scanner.Err(); err != nil {
+			s.err = fmt.Errorf("error scanning line %d: %w", s.line+1, err)
+		}
+	}
+}
+
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// Chunker reads an input in chunks of at most a given size, for solvers that
+// consume it a byte at a time and must not care where the chunks end.
+type Chunker struct {
+	r      io.Reader
+	buf    []byte
+	offset int64
+	err    error
+}
+
+func NewChunker(r io.Reader, size int) *Chunker {
+	return &Chunker{r: r, buf: make([]byte, max(size, 1))}
+}
+
+// Chunks yields the offset and the bytes of every chunk, which are only valid
+// until the next one. Check Err once the loop is done.
+func (c *Chunker) Chunks() iter.Seq2[int64, []byte] {
+	return func(yield func(int64, []byte) bool) {
+		for {
+			n, err := c.r.Read(c.buf)
+			if n > 0 {
+				if !yield(c.offset, c.buf[:n]) {
+					return
+				}
+				c.offset += int64(n)
+			}
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				c.err = fmt.Errorf("error reading at offset %d: %w", c.offset, err)
+				return
+			}
+		}
+	}
+}
+
+func (c *Chunker) Err() error {
+	return c.err
+}
+
+// Window reads single bytes at arbitrary offsets of r, keeping only the
+// window around the last offset in memory. Reads close to the previous one,
+// forwards or backwards, are served from the window.
+type Window struct {
+	r      io.ReaderAt
+	size   int64
+	buffer []byte
+	// start is the offset of buffer[0], buffer holds the bytes up to end
+	start, end int64
+	err        error
+}
+
+// NewWindow reads r, which holds size bytes, through a window of windowSize
+// bytes.
+func NewWindow(r io.ReaderAt, size int64, windowSize int) *Window {
+	return &Window{r: r, size: size, buffer: make([]byte, windowSize)}
+}
+
+func (w *Window) Size() int64 {
+	return w.size
+}
+
+// At returns the byte at offset, or 0 after an error, see Err.
+func (w *Window) At(offset int64) byte {
+	if offset < w.start || offset >= w.end {
+		w.load(offset)
+		if w.err != nil {
+			return 0
+		}
+	}
+	return w.buffer[offset-w.start]
+}
+
+// load centres the window on offset so that walking in either direction
+// stays inside it for a while.
+func (w *Window) load(offset int64) {
+	if w.err != nil {
+		return
+	}
+	if offset < 0 || offset >= w.size {
+		w.err = fmt.Errorf("offset %d out of range [0, %d)", offset, w.size)
+		return
+	}
+
+	start := max(0, min(offset-int64(len(w.buffer))/2, w.size-int64(len(w.buffer))))
+	n, err := w.r.ReadAt(w.buffer, start)
+	if err != nil && err != io.EOF {
+		w.err = fmt.Errorf("error reading at %d: %w", start, err)
+		return
+	}
+	w.start, w.end = start, start+int64(n)
+}
+
+func (w *Window) Err() error {
+	return w.err
+}
@@ -0,0 +1,143 @@
+// Package trace lets solvers emit the intermediate steps of a simulation as
+// JSON Lines, one Event per line, to be stepped through with aoc replay.
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type Kind string
+
+const (
+	// KindSnapshot events hold the full state at some point.
+	KindSnapshot Kind = "snapshot"
+	// KindDecision events describe a choice the solver made.
+	KindDecision Kind = "decision"
+)
+
+type Event struct {
+	Seq   int    `json:"seq"`
+	Kind  Kind   `json:"kind"`
+	Scope string `json:"scope"`
+	// Message describes the event in a line of text.
+	Message string `json:"message,omitempty"`
+	State   any    `json:"state,omitempty"`
+}
+
+// Tracer receives the steps of a solver. Snapshots can be costly to build, so
+// solvers check Enabled first in hot paths.
+type Tracer interface {
+	Enabled() bool
+	Snapshot(scope, message string, state any)
+	Decision(scope, format string, args ...any)
+	Close() error
+}
+
+// Open returns a Tracer writing to path, or a Tracer discarding everything if
+// path is empty.
+func Open(path string) (Tracer, error) {
+	if path == "" {
+		return Nop{}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace: %w", err)
+	}
+
+	w := bufio.NewWriter(file)
+	return &writer{file: file, buffer: w, encoder: json.NewEncoder(w)}, nil
+}
+
+// Nop discards all events.
+type Nop struct{}
+
+func (Nop) Enabled() bool                              { return false }
+func (Nop) Snapshot(scope, message string, state any)  {}
+func (Nop) Decision(scope, format string, args ...any) {}
+func (Nop) Close() error                               { return nil }
+
+type writer struct {
+	file    *os.File
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+	seq     int
+	err     error
+}
+
+func (w *writer) Enabled() bool { return true }
+
+func (w *writer) Snapshot(scope, message string, state any) {
+	w.write(Event{Kind: KindSnapshot, Scope: scope, Message: message, State: state})
+}
+
+func (w *writer) Decision(scope, format string, args ...any) {
+	w.write(Event{Kind: KindDecision, Scope: scope, Message: fmt.Sprintf(format, args...)})
+}
+
+// write keeps the first error and reports it on Close, so solvers don't have
+// to check every event.
+func (w *writer) write(event Event) {
+	if w.err != nil {
+		return
+	}
+	event.Seq = w.seq
+	w.seq++
+	w.err = w.encoder.Encode(event)
+}
+
+func (w *writer) Close() error {
+	if err := w.buffer.Flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+	if err := w.file.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+	if w.err != nil {
+		return fmt.Errorf("error writing trace: %w", w.err)
+	}
+	return nil
+}
+
+// ReadFile reads all events of a trace.
+func ReadFile(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening trace: %w", err)
+	}
+	defer file.Close()
+
+	events := make([]Event, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("error parsing trace line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading trace: %w", err)
+	}
+
+	return events, nil
+}
+
+// GridState is a snapshot of a character grid with a few extra values.
+type GridState struct {
+	Grid []string       `json:"grid"`
+	Info map[string]any `json:"info,omitempty"`
+}
+
+func Grid(rows [][]rune, info map[string]any) GridState {
+	grid := make([]string, len(rows))
+	for i, row := range rows {
+		grid[i] = string(row)
+	}
+	return GridState{Grid: grid, Info: info}
+}
@@ -0,0 +1,126 @@
+package visual
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Colors returns the CSS color of every cell as shown at the given frame,
+// indexed [y][x].
+func (v *Visualization) Colors(frame int) [][]string {
+	colors := make([][]string, v.Height)
+	for y := range colors {
+		colors[y] = make([]string, v.Width)
+		var row []rune
+		if y < len(v.Grid) {
+			row = []rune(v.Grid[y])
+		}
+		for x := range colors[y] {
+			char := ' '
+			if x < len(row) {
+				char = row[x]
+			}
+			colors[y][x] = v.BackgroundColor(char)
+		}
+	}
+
+	first := frame
+	if v.Cumulative {
+		first = 0
+	}
+	for i := max(first, 0); i <= frame && i < len(v.Frames); i++ {
+		for _, cell := range v.Frames[i].Cells {
+			if cell.Y >= 0 && cell.Y < v.Height && cell.X >= 0 && cell.X < v.Width {
+				colors[cell.Y][cell.X] = cell.Color
+			}
+		}
+	}
+
+	return colors
+}
+
+// BackgroundColor returns the color of a background character, from the
+// palette if it has one. Keep in sync with backgroundColor in the web UI.
+func (v *Visualization) BackgroundColor(char rune) string {
+	if color, ok := v.Palette[string(char)]; ok {
+		return color
+	}
+
+	switch {
+	case char == '#':
+		return "#444"
+	case char == '.' || char == ' ':
+		return "#f4f4f4"
+	case char >= '0' && char <= '9':
+		return fmt.Sprintf("hsl(0, 0%%, %d%%)", 95-int(char-'0')*6)
+	}
+	return fmt.Sprintf("hsl(%d, 25%%, 88%%)", (int(char)*47)%360)
+}
+
+var namedColors = map[string][3]uint8{
+	"black": {0, 0, 0},
+	"white": {255, 255, 255},
+	"red":   {255, 0, 0},
+	"green": {0, 128, 0},
+	"blue":  {0, 0, 255},
+}
+
+// RGB converts the CSS colors used by visualizations (#rgb, #rrggbb, hsl()
+// and a few names) to RGB.
+func RGB(color string) (r, g, b uint8, ok bool) {
+	color = strings.TrimSpace(strings.ToLower(color))
+	if rgb, ok := namedColors[color]; ok {
+		return rgb[0], rgb[1], rgb[2], true
+	}
+
+	if hex, found := strings.CutPrefix(color, "#"); found {
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		value, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return 0, 0, 0, false
+		}
+		return uint8(value >> 16), uint8(value >> 8), uint8(value), true
+	}
+
+	if args, found := strings.CutPrefix(color, "hsl("); found {
+		var h, s, l float64
+		args = strings.NewReplacer("%", "", ",", " ", ")", "").Replace(args)
+		if _, err := fmt.Sscan(args, &h, &s, &l); err != nil {
+			return 0, 0, 0, false
+		}
+		r, g, b := hslToRGB(h, s/100, l/100)
+		return r, g, b, true
+	}
+
+	return 0, 0, 0, false
+}
+
+func hslToRGB(h, s, l float64) (r, g, b uint8) {
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := math.Mod(h, 360) / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+
+	var r1, g1, b1 float64
+	switch {
+	case hp < 1:
+		r1, g1 = c, x
+	case hp < 2:
+		r1, g1 = x, c
+	case hp < 3:
+		g1, b1 = c, x
+	case hp < 4:
+		g1, b1 = x, c
+	case hp < 5:
+		r1, b1 = x, c
+	default:
+		r1, b1 = c, x
+	}
+
+	m := l - c/2
+	scale := func(v float64) uint8 { return uint8(math.Round((v + m) * 255)) }
+	return scale(r1), scale(g1), scale(b1)
+}
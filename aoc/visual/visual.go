@@ -0,0 +1,115 @@
+// Package visual records step-by-step states of grid puzzles as JSON, which
+// the web UI of aoc serve renders on a canvas.
+package visual
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Visualization is a grid background with a sequence of frames drawn over it.
+type Visualization struct {
+	Title  string `json:"title"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// Grid holds the background rows, it may be empty.
+	Grid []string `json:"grid,omitempty"`
+	// Palette maps background characters to CSS colors.
+	Palette map[string]string `json:"palette,omitempty"`
+	// Cumulative frames are drawn on top of all previous ones, otherwise every
+	// frame replaces the previous one.
+	Cumulative bool    `json:"cumulative"`
+	Frames     []Frame `json:"frames"`
+}
+
+type Frame struct {
+	Label string `json:"label"`
+	Cells []Cell `json:"cells"`
+}
+
+type Cell struct {
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Color string `json:"color"`
+}
+
+// FromRunes returns a visualization with the given rows as background.
+func FromRunes(title string, grid [][]rune) *Visualization {
+	rows := make([]string, len(grid))
+	for i, row := range grid {
+		rows[i] = string(row)
+	}
+	return fromRows(title, rows)
+}
+
+// FromDigits returns a visualization with a background of single digits.
+func FromDigits(title string, grid [][]int) *Visualization {
+	rows := make([]string, len(grid))
+	for i, row := range grid {
+		runes := make([]rune, len(row))
+		for j, digit := range row {
+			runes[j] = '0' + rune(digit)
+		}
+		rows[i] = string(runes)
+	}
+	return fromRows(title, rows)
+}
+
+func fromRows(title string, rows []string) *Visualization {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len([]rune(row)))
+	}
+
+	v := Empty(title, width, len(rows))
+	v.Grid = rows
+	return v
+}
+
+// Empty returns a visualization without background.
+func Empty(title string, width, height int) *Visualization {
+	return &Visualization{
+		Title:   title,
+		Width:   width,
+		Height:  height,
+		Palette: map[string]string{},
+		Frames:  []Frame{},
+	}
+}
+
+func (v *Visualization) AddFrame(label string, cells []Cell) {
+	v.Frames = append(v.Frames, Frame{Label: label, Cells: cells})
+}
+
+// WriteFile writes the visualization as JSON to path, "-" meaning stdout.
+func (v *Visualization) WriteFile(path string) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error encoding visualization: %w", err)
+	}
+
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing visualization: %w", err)
+	}
+
+	return nil
+}
+
+// Color returns a distinct CSS color for the i-th element of a sequence, e.g.
+// for the i-th region of a map.
+func Color(i int) string {
+	// golden angle steps keep neighbouring indices far apart on the hue circle
+	return fmt.Sprintf("hsl(%d, 70%%, 55%%)", (i*137)%360)
+}
+
+// Gradient returns a CSS color between blue (0) and red (1) for value in [0, 1].
+func Gradient(value float64) string {
+	value = min(max(value, 0), 1)
+	return fmt.Sprintf("hsl(%d, 80%%, 50%%)", int(240*(1-value)))
+}